@@ -0,0 +1,125 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	// a11yLintFile is the name of the file declaring the accessibility checks of the constructors, generated with
+	// -a11y-lint.
+	a11yLintFile = "a11ylint.go"
+
+	// a11yLintTag is the build tag that enables the accessibility checks. The files a11yLintOnFile and
+	// a11yLintOffFile declare the constant a11yLint with and without it, so that the checks are compiled out of the
+	// builds without the tag.
+	a11yLintTag     = "a11ylint"
+	a11yLintOnFile  = "a11ylint_on.go"
+	a11yLintOffFile = "a11ylint_off.go"
+)
+
+// a11yLints maps the elements whose constructors check for common accessibility mistakes with -a11y-lint to the
+// function of a11yLintFile that checks them and its arguments: the props fields of the attributes named, the tag of
+// the element for "tag", the Role of the props for "role" and the children of the element for "children".
+var a11yLints = map[string]struct {
+	Func string
+	Args []string
+}{
+	"a":      {"lintLink", []string{"href", "children"}},
+	"button": {"lintName", []string{"tag", "children"}},
+	"img":    {"lintImg", []string{"alt", "role"}},
+	"th":     {"lintTh", []string{"scope"}},
+}
+
+// templA11yLintMode is the data of the template that declares the constant a11yLint, whose value is On in the builds
+// that satisfy ModeConstraint.
+type templA11yLintMode struct {
+	templTarget
+
+	On             bool
+	ModeConstraint string
+}
+
+// A11yLint returns the call of the function that checks e for accessibility mistakes, see a11yLints, or "" if there is
+// none, e lacks one of its string or value type attributes or -a11y-lint is not set.
+func (e templElem) A11yLint() string {
+	c, ok := a11yLints[e.Name]
+	if !ok || !e.Lint {
+		return ""
+	}
+	fields := make(map[string]string)
+	for _, a := range e.ElemAttrs() {
+		switch {
+		case a.Type == "string":
+			fields[a.JS] = "rProps." + a.Name
+		case isValueType(a.Type):
+			fields[a.JS] = "string(rProps." + a.Name + ")"
+		}
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		switch a {
+		case "tag":
+			args = append(args, strconv.Quote(e.Name))
+		case "role":
+			args = append(args, "rProps.Role")
+		case "children":
+			args = append(args, "children")
+		default:
+			f, ok := fields[a]
+			if !ok {
+				return ""
+			}
+			args = append(args, f)
+		}
+	}
+
+	return c.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+// generateA11yLint renders with base the accessibility checks into a11yLintFile and the constant enabling them into
+// a11yLintOnFile and a11yLintOffFile, and writes or diffs them according to opts.
+func generateA11yLint(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "a11ylint", a11yLintFile, target, opts, res, produced)
+
+	build := buildExpr(opts)
+	for _, on := range []bool{true, false} {
+		expr, file := a11yLintTag, a11yLintOnFile
+		if !on {
+			expr, file = "!"+a11yLintTag, a11yLintOffFile
+		}
+		if build != "" && build != "none" {
+			expr = "(" + build + ") && " + expr
+		}
+		// The expression is valid, since the build constraint of target is.
+		lines, _ := constraintLines(expr)
+		data := templA11yLintMode{templTarget: target, On: on, ModeConstraint: lines}
+		generateShared(base, "lintmode", file, data, opts, res, produced)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BreakingChange is an identifier of the exported API of the generated package that regeneration removes or whose
+// type it changes. Old and New describe its type before and after; New is empty if it is removed.
+type BreakingChange struct {
+	Path  string
+	Ident string
+	Old   string
+	New   string
+}
+
+func (c BreakingChange) String() string {
+	if c.New == "" {
+		return fmt.Sprintf("%s: %s removed", c.Path, c.Ident)
+	}
+	return fmt.Sprintf("%s: %s changed from %s to %s", c.Path, c.Ident, c.Old, c.New)
+}
+
+// apiEntry is the type of an identifier of the API and the file declaring it.
+type apiEntry struct {
+	Path, Type string
+}
+
+// apiDelta accumulates the API declared by the old and the new versions of the files a run changes.
+type apiDelta struct {
+	old, new map[string]apiEntry
+}
+
+// record adds the API declared by the old and new contents of the file p; either may be nil. Sources that do not
+// parse contribute nothing.
+func (d *apiDelta) record(p string, old, new []byte) {
+	if d.old == nil {
+		d.old, d.new = make(map[string]apiEntry), make(map[string]apiEntry)
+	}
+	for _, side := range []struct {
+		src []byte
+		api map[string]apiEntry
+	}{{old, d.old}, {new, d.new}} {
+		if side.src == nil {
+			continue
+		}
+		api, err := apiOf(side.src)
+		if err != nil {
+			continue
+		}
+		for id, t := range api {
+			side.api[id] = apiEntry{p, t}
+		}
+	}
+}
+
+// merge adds the API recorded by o to d.
+func (d *apiDelta) merge(o apiDelta) {
+	if o.old == nil {
+		return
+	}
+	if d.old == nil {
+		d.old, d.new = make(map[string]apiEntry), make(map[string]apiEntry)
+	}
+	for id, e := range o.old {
+		d.old[id] = e
+	}
+	for id, e := range o.new {
+		d.new[id] = e
+	}
+}
+
+// breaking returns the identifiers of the old API that the new one removes or changes, ordered by file and name.
+func (d *apiDelta) breaking() []BreakingChange {
+	var changes []BreakingChange
+	for id, o := range d.old {
+		n, ok := d.new[id]
+		switch {
+		case !ok:
+			changes = append(changes, BreakingChange{Path: o.Path, Ident: id, Old: o.Type})
+		case n.Type != o.Type:
+			changes = append(changes, BreakingChange{Path: o.Path, Ident: id, Old: o.Type, New: n.Type})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Ident < changes[j].Ident
+	})
+
+	return changes
+}
+
+// apiOf returns the API declared by the Go source src: its exported constants, variables, functions and types,
+// the exported methods and fields of its types, keyed by name (Type.Member for members), and a description of the
+// type of each. The props structs named _<Name>, from which the exported <Name> props types are derived, count as
+// exported.
+func apiOf(src []byte) (map[string]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(n ast.Node) string {
+		var b bytes.Buffer
+		printer.Fprint(&b, fset, n)
+		return b.String()
+	}
+	api := make(map[string]string)
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			name := d.Name.Name
+			if d.Recv != nil {
+				recv := strings.TrimPrefix(str(d.Recv.List[0].Type), "*")
+				if !apiExported(recv) {
+					continue
+				}
+				name = recv + "." + name
+			}
+			api[name] = signature(d.Type, str)
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if !n.IsExported() {
+							continue
+						}
+						t := d.Tok.String()
+						if s.Type != nil {
+							t += " " + str(s.Type)
+						}
+						api[n.Name] = t
+					}
+				case *ast.TypeSpec:
+					if !apiExported(s.Name.Name) {
+						continue
+					}
+					switch t := s.Type.(type) {
+					case *ast.StructType:
+						api[s.Name.Name] = "struct"
+						for _, fld := range t.Fields.List {
+							ft := str(fld.Type)
+							if len(fld.Names) == 0 {
+								api[s.Name.Name+"."+strings.TrimPrefix(ft, "*")] = "embedded " + ft
+							}
+							for _, n := range fld.Names {
+								if n.IsExported() {
+									api[s.Name.Name+"."+n.Name] = ft
+								}
+							}
+						}
+					case *ast.InterfaceType:
+						api[s.Name.Name] = "interface"
+						for _, m := range t.Methods.List {
+							for _, n := range m.Names {
+								if n.IsExported() {
+									api[s.Name.Name+"."+n.Name] = str(m.Type)
+								}
+							}
+						}
+					default:
+						api[s.Name.Name] = "type " + str(s.Type)
+					}
+				}
+			}
+		}
+	}
+
+	return api, nil
+}
+
+// apiExported reports whether the type name belongs to the API: it is exported or it is a props struct _<Name>.
+func apiExported(name string) bool {
+	name = strings.TrimPrefix(name, "_")
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// signature describes the function type t by its parameter and result types, leaving out the names, which callers
+// do not depend on.
+func signature(t *ast.FuncType, str func(ast.Node) string) string {
+	types := func(l *ast.FieldList) []string {
+		var ts []string
+		if l == nil {
+			return nil
+		}
+		for _, f := range l.List {
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				ts = append(ts, str(f.Type))
+			}
+		}
+		return ts
+	}
+
+	s := "func(" + strings.Join(types(t.Params), ", ") + ")"
+	switch rs := types(t.Results); len(rs) {
+	case 0:
+	case 1:
+		s += " " + rs[0]
+	default:
+		s += " (" + strings.Join(rs, ", ") + ")"
+	}
+
+	return s
+}
@@ -0,0 +1,129 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// archiveTime is the modification time of every archived file, fixed so that generating the same files always
+// produces the same archive.
+var archiveTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// archiveFormat returns the format of the archive p given by its extension: "zip", "tar" or "tgz".
+func archiveFormat(p string) (string, error) {
+	switch {
+	case strings.HasSuffix(p, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(p, ".tar"):
+		return "tar", nil
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		return "tgz", nil
+	}
+
+	return "", fmt.Errorf("archive %s: unknown format (want .zip, .tar, .tar.gz or .tgz)", p)
+}
+
+// writeArchive packages the files of fsys into the archive p, in the format given by archiveFormat. The archive is
+// built in memory and replaces p atomically.
+func writeArchive(p string, fsys *MemFS) error {
+	format, err := archiveFormat(p)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "zip":
+		err = writeZip(&buf, fsys)
+	case "tar":
+		err = writeTar(&buf, fsys)
+	case "tgz":
+		zw := gzip.NewWriter(&buf)
+		err = writeTar(zw, fsys)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("archive %s: %v", p, err)
+	}
+
+	return writeFile(p, buf.Bytes())
+}
+
+// writeZip writes the files of fsys to w as a zip archive.
+func writeZip(w io.Writer, fsys *MemFS) error {
+	zw := zip.NewWriter(w)
+	for _, n := range fsys.Files() {
+		data, err := fsys.ReadFile(n)
+		if err != nil {
+			return err
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: n, Method: zip.Deflate, Modified: archiveTime})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeTar writes the files of fsys to w as a tar archive.
+func writeTar(w io.Writer, fsys *MemFS) error {
+	tw := tar.NewWriter(w)
+	for _, n := range fsys.Files() {
+		data, err := fsys.ReadFile(n)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     n,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			ModTime:  archiveTime,
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
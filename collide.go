@@ -0,0 +1,210 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// handWritten lists the identifiers already declared by the hand-written parts of the react package: the core types
+// the generated code builds on and the elements that are commented out of the element table. Generated identifiers
+// must not clash with them.
+var handWritten = []string{
+	"BasicHTMLElement", "Element", "S",
+
+	"A", "AElem", "AProps",
+	"Br", "BrElem", "BrProps",
+	"Button", "ButtonElem", "ButtonProps",
+	"Code", "CodeElem", "CodeProps",
+	"Div", "DivElem", "DivProps",
+	"Footer", "FooterElem", "FooterProps",
+	"Form", "FormElem", "FormProps",
+	"H1", "H1Elem", "H1Props",
+	"H3", "H3Elem", "H3Props",
+	"H4", "H4Elem", "H4Props",
+	"Hr", "HrElem", "HrProps",
+	"I", "IElem", "IProps",
+	"IFrame", "IFrameElem", "IFrameProps",
+	"Img", "ImgElem", "ImgProps",
+	"Input", "InputElem", "InputProps",
+	"Label", "LabelElem", "LabelProps",
+	"Li", "LiElem", "LiProps",
+	"Nav", "NavElem", "NavProps",
+	"Option", "OptionElem", "OptionProps",
+	"P", "PElem", "PProps",
+	"Pre", "PreElem", "PreProps",
+	"Select", "SelectElem", "SelectProps",
+	"Span", "SpanElem", "SpanProps",
+	"Table", "TableElem", "TableProps",
+	"TextArea", "TextAreaElem", "TextAreaProps",
+	"Ul", "UlElem", "UlProps",
+}
+
+// sharedDecls names the owner of the identifiers declared in optionsFile, classesFile, debugFile, registryFile,
+// shallowFile, metaFile, eventsFile, styleFile, formValuesFile, enumsFile, headBuilderFile, socialMetaFile, rolesFile
+// and the files of the valueTypes in collision reports.
+const sharedDecls = "the shared declarations"
+
+// findCollisions reports every identifier that more than one element or attribute group would declare, or that one
+// would declare although it is reserved, together with the attribute fields that clash within a single props struct or
+// with a property of BasicHTMLElement, including the Ref field if refs is set, and the declarations of styleFile if
+// typedStyle is. The elements are visited in the order of tags.
+func findCollisions(tags []string, elems map[string]templElem, reserved []string, refs, typedStyle bool) []Failure {
+	owners := make(map[string][]string)
+	for _, id := range reserved {
+		owners[id] = append(owners[id], "")
+	}
+
+	for _, a := range basicAttrs {
+		owners["With"+a.Override] = append(owners["With"+a.Override], sharedDecls)
+	}
+	for _, id := range []string{"BasicOption", "CreateByTag", "ElementsByTag", "ShallowElement", "Shallow", "FindShallow",
+		"ElementMeta", "AttrMeta", "ElementsMeta", "ClassMap", "Classes", "WithClasses", "FormValues", "FormJSON", "Debug",
+		"DefaultViewport", "HeadBuilder", "NewHead"} {
+		owners[id] = append(owners[id], sharedDecls)
+	}
+	for id := range eventHandlers {
+		owners[id] = append(owners[id], sharedDecls)
+	}
+	for _, id := range roleDecls() {
+		owners[id] = append(owners[id], sharedDecls)
+	}
+	for _, id := range socialMetaDecls() {
+		owners[id] = append(owners[id], sharedDecls)
+	}
+	for _, id := range enumDecls() {
+		owners[id] = append(owners[id], sharedDecls)
+	}
+	for k, h := range elemHelpers {
+		for _, id := range h.Decls {
+			owners[id] = append(owners[id], k)
+		}
+	}
+	for _, t := range valueTypes {
+		for _, id := range t.Decls {
+			owners[id] = append(owners[id], sharedDecls)
+		}
+	}
+	if typedStyle {
+		for _, id := range styleDecls {
+			owners[id] = append(owners[id], sharedDecls)
+		}
+	}
+
+	var failures []Failure
+	groups := make(map[*templGroup]bool)
+	for _, k := range tags {
+		e := elems[k]
+		for _, id := range []string{e.Upper, e.Elem, e.Props, "_" + e.Props, e.Upper + "Opt", e.Option, e.OptionFunc} {
+			owners[id] = append(owners[id], k)
+		}
+		if refs && len(e.RefMethods) > 0 {
+			owners[e.Upper+"Ref"] = append(owners[e.Upper+"Ref"], k)
+		}
+		for _, f := range e.Formerly {
+			for _, id := range []string{f, f + "Elem", f + "Props"} {
+				owners[id] = append(owners[id], k)
+			}
+		}
+
+		attrs := e.Attrs
+		for _, g := range e.Groups {
+			if !groups[g] {
+				groups[g] = true
+				owners[g.Type] = append(owners[g.Type], "attribute group "+g.Name)
+			}
+			attrs = append(append([]templAttr(nil), g.Attrs...), attrs...)
+		}
+
+		fields := make(map[string]string)
+		props := make(map[string]bool)
+		for _, a := range basicAttrs {
+			fields[a.Override] = "BasicHTMLElement." + a.Name
+			props[a.Name] = true
+		}
+		if refs {
+			fields["Ref"] = "the Ref callback"
+		}
+		for _, a := range attrs {
+			if props[a.JS] {
+				// React reads key itself rather than passing it to the DOM, and the other properties of
+				// BasicHTMLElement would be set twice.
+				failures = append(failures, Failure{
+					Element: k,
+					Phase:   PhaseSpec,
+					Err:     fmt.Errorf("attribute %q is a property of BasicHTMLElement, which every element has", a.JS),
+				})
+				continue
+			}
+			if prev, ok := fields[a.Name]; ok {
+				failures = append(failures, Failure{
+					Element: k,
+					Phase:   PhaseSpec,
+					Err:     fmt.Errorf("attributes %q and %q both map to field %s; set an Override on one of them", prev, a.JS, a.Name),
+				})
+			}
+			fields[a.Name] = a.JS
+		}
+	}
+
+	var ids []string
+	for id, o := range owners {
+		if len(o) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var tags []string
+		reserved := false
+		for _, k := range owners[id] {
+			switch {
+			case k == "":
+				reserved = true
+			case strings.HasPrefix(k, "attribute group "), k == sharedDecls:
+				tags = append(tags, k)
+			default:
+				tags = append(tags, "<"+k+">")
+			}
+		}
+
+		var err error
+		if reserved {
+			err = fmt.Errorf("identifier %s generated for %s is already declared by hand-written code; set an Override", id, strings.Join(tags, ", "))
+		} else {
+			err = fmt.Errorf("identifier %s is generated for each of %s; set an Override on all but one", id, strings.Join(tags, ", "))
+		}
+		f := Failure{Phase: PhaseSpec, Err: err}
+		if len(tags) > 0 && strings.HasPrefix(tags[0], "<") {
+			f.Element = strings.Trim(tags[0], "<>")
+		}
+		failures = append(failures, f)
+	}
+
+	return failures
+}
@@ -0,0 +1,287 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	// name is the name of the command and aliases its other names.
+	name    string
+	aliases []string
+
+	// args describes the arguments that follow the flags, summary the command in a line and help in full.
+	args, summary, help string
+
+	// shared lists the flags of the generate command that the command accepts too, or all of them if nil.
+	shared []string
+
+	// run parses args with fs, which holds the shared flags, and runs the command, returning the exit status.
+	run func(fs *flag.FlagSet, args []string) int
+}
+
+// tableFlags are the flags that select the element table, accepted by the commands working on it.
+var tableFlags = []string{
+	"project-config", "config", "config-sha256", "overlay", "package", "import-path", "all", "experimental", "strict", "bcd",
+	"min-browsers", "only", "skip", "cache-dir", "cache-ttl", "offline", "rate-limit",
+}
+
+// logFlags are the flags controlling the log messages, accepted by every command.
+var logFlags = []string{"q", "v", "log-format"}
+
+// commands lists the commands of the tool, the first being the default.
+var commands []command
+
+func init() {
+	commands = []command{
+		{
+			name:    "generate",
+			summary: "generate the element wrappers (the default command)",
+			help: `generate writes the Go wrappers of the elements of the element table, and the files shared by them, into the
+output directory.`,
+			run: runGenerate,
+		},
+		{
+			name:    "list",
+			args:    "",
+			summary: "print the elements that would be generated",
+			help:    `list prints the elements that would be generated with their Go names and, with -attrs, their attributes.`,
+			shared:  tableFlags,
+			run:     runList,
+		},
+		{
+			name:    "convert",
+			aliases: []string{"html2go"},
+			args:    "[file]",
+			summary: "convert HTML or JSX into calls to the element constructors",
+			help: `convert converts the HTML fragment in file, or standard input, into calls to the element constructors of the
+package described by the flags and prints them. With -jsx it reads JSX instead.`,
+			shared: tableFlags,
+			run:    runHTML2Go,
+		},
+		{
+			name:    "coverage",
+			summary: "compare the element table with the HTML standard",
+			help: `coverage compares the element table with the elements of the WHATWG HTML standard, listing those that are
+hand-written, missing, or not standard.`,
+			shared: tableFlags,
+			run:    runCoverage,
+		},
+		{
+			name:    "lint",
+			args:    "dir",
+			summary: "check hand-written elements against the spec",
+			help: `lint compares the props of the hand-written *_elem.go files in dir with the spec, reporting attributes that are
+missing, misnamed, mistyped, or not in the spec.`,
+			shared: tableFlags,
+			run:    runLint,
+		},
+		{
+			name:    "import",
+			args:    "dir",
+			summary: "derive spec entries from hand-written elements",
+			help: `import prints a spec whose elements reproduce the props of the hand-written *_elem.go files in dir, naming
+overrides only where the naming rules differ, as a baseline for generating them.`,
+			shared: tableFlags,
+			run:    runImport,
+		},
+		{
+			name:    "validate",
+			args:    "spec.json...",
+			summary: "check spec files for problems",
+			help: `validate checks spec files for duplicate keys, elements and attributes, unknown attribute types, overrides that
+do not change a name, and identifiers that collide, reporting each problem with its line and column.`,
+			shared: []string{},
+			run:    runValidate,
+		},
+		{
+			name:    "diff",
+			args:    "[old.json] new.json",
+			summary: "compare the element tables of two specs",
+			help: `diff reports the elements and attributes added to, removed from, or changed in the spec new.json compared with
+old.json or, if it is omitted, the embedded element catalog. With -changelog it records them, under the version of
+new.json, in a Markdown changelog kept next to the spec.`,
+			shared: []string{},
+			run:    runDiff,
+		},
+		{
+			name:    "completion",
+			args:    "bash|zsh|fish",
+			summary: "print a shell completion script",
+			help: `completion prints the script completing the commands, flags, and the element names of -only and -skip in
+the given shell. The element names are those of the table the flags select. For example:
+
+	source <(elemental completion bash)`,
+			shared: tableFlags,
+			run:    runCompletion,
+		},
+	}
+}
+
+// lookupCommand returns the command called name.
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+		for _, a := range c.aliases {
+			if a == name {
+				return c, true
+			}
+		}
+	}
+
+	return command{}, false
+}
+
+// flagSet returns the flag set of c, holding the flags of the generate command that it shares. They are bound to the
+// same variables, so that flags given before the command name still apply.
+func (c command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	if c.shared == nil {
+		commandLine.VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+	} else {
+		for _, n := range c.shared {
+			f := commandLine.Lookup(n)
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	for _, n := range logFlags {
+		if f := commandLine.Lookup(n); fs.Lookup(n) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	fs.Usage = func() { c.usage(fs) }
+
+	return fs
+}
+
+// allFlags returns the flag set of c including the flags of its own, which the command declares as it runs: it is
+// run with -h and its usage silenced.
+func (c command) allFlags() *flag.FlagSet {
+	fs := c.flagSet()
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	c.run(fs, []string{"-h"})
+	fs.SetOutput(nil)
+	fs.Usage = func() { c.usage(fs) }
+
+	return fs
+}
+
+// usage prints the help of c, with the flags of fs.
+func (c command) usage(fs *flag.FlagSet) {
+	synopsis := c.name + " [flags]"
+	if c.args != "" {
+		synopsis += " " + c.args
+	}
+	fmt.Fprintf(os.Stderr, "Usage: %s %s\n\n%s\n", path.Base(os.Args[0]), synopsis, c.help)
+	if len(c.aliases) > 0 {
+		fmt.Fprintf(os.Stderr, "\nAlso called %s.\n", strings.Join(c.aliases, ", "))
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+// parseArgs parses the arguments args of a command with fs and applies the project configuration to the flags that
+// neither args nor the command line before the command name set. It returns false, with the exit status, if the
+// command should not run.
+func parseArgs(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0, false
+		}
+		return exitUsage, false
+	}
+
+	if p := *projectConfig; p != "none" {
+		if p == "" {
+			if wd, err := os.Getwd(); err == nil {
+				p = findProjectConfig(wd)
+			}
+		}
+		if p != "" {
+			if err := applyProjectConfig(fs, p); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitUsage, false
+			}
+		}
+	}
+
+	if err := configureLogger(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage, false
+	}
+
+	return 0, true
+}
+
+// runGenerate runs the generate command with the arguments args, once or, with -watch, whenever its inputs change.
+// It returns the exit status.
+func runGenerate(fs *flag.FlagSet, args []string) int {
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	if *interactive {
+		return runInteractive(fs)
+	}
+	if *watch {
+		return watchAndRun(*watchInterval)
+	}
+	return run()
+}
+
+// runHelp runs the help command, printing the help of the command named by args or, without one, of the tool. It
+// returns the exit status.
+func runHelp(args []string) int {
+	switch len(args) {
+	case 0:
+		usage()
+		return 0
+	case 1:
+		c, ok := lookupCommand(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+			return exitUsage
+		}
+		c.usage(c.allFlags())
+		return 0
+	}
+
+	usage()
+	return exitUsage
+}
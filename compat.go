@@ -0,0 +1,274 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// compatBrowsers are the browsers whose support is noted in the documentation of the generated attributes, with the
+// oldest version of each that the notes assume when -min-browsers does not name it.
+var compatBrowsers = map[string]string{
+	"chrome":  "80",
+	"edge":    "80",
+	"firefox": "75",
+	"safari":  "13",
+}
+
+// browserNames are the display names of the compatBrowsers.
+var browserNames = map[string]string{
+	"chrome":  "Chrome",
+	"edge":    "Edge",
+	"firefox": "Firefox",
+	"safari":  "Safari",
+}
+
+type (
+	// bcdData is the part of MDN's browser-compat-data (https://github.com/mdn/browser-compat-data) describing
+	// HTML elements: the support of each attribute, keyed by element and attribute name.
+	bcdData struct {
+		HTML struct {
+			Elements map[string]map[string]json.RawMessage `json:"elements"`
+		} `json:"html"`
+	}
+
+	// bcdFeature is a feature of browser-compat-data. Each support statement is a single bcdSupport or an array of
+	// them, the first of which describes the current release.
+	bcdFeature struct {
+		Compat struct {
+			Support map[string]json.RawMessage `json:"support"`
+		} `json:"__compat"`
+	}
+
+	bcdSupport struct {
+		VersionAdded   interface{}       `json:"version_added"`
+		VersionRemoved interface{}       `json:"version_removed"`
+		Flags          []json.RawMessage `json:"flags"`
+	}
+)
+
+// loadBCD reads the browser-compat-data file p and returns the version of each browser that added each attribute,
+// keyed by element and attribute name, with "" for a browser that does not support it.
+func loadBCD(p string) (map[string]map[string]map[string]string, error) {
+	b, err := readInput(p)
+	if err != nil {
+		return nil, err
+	}
+	var data bcdData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+
+	compat := make(map[string]map[string]map[string]string)
+	for k, attrs := range data.HTML.Elements {
+		for n, raw := range attrs {
+			if strings.HasPrefix(n, "__") {
+				continue
+			}
+			var f bcdFeature
+			if err := json.Unmarshal(raw, &f); err != nil {
+				return nil, fmt.Errorf("%s: html.elements.%s.%s: %v", p, k, n, err)
+			}
+			added := make(map[string]string)
+			for browser, s := range f.Compat.Support {
+				added[browser] = versionAdded(s)
+			}
+			if compat[k] == nil {
+				compat[k] = make(map[string]map[string]string)
+			}
+			compat[k][n] = added
+		}
+	}
+
+	return compat, nil
+}
+
+// versionAdded returns the version in which the support statement raw says the feature became available without a
+// flag in the current release, or "" if it is not.
+func versionAdded(raw json.RawMessage) string {
+	var s bcdSupport
+	if err := json.Unmarshal(raw, &s); err != nil {
+		var list []bcdSupport
+		if json.Unmarshal(raw, &list) != nil || len(list) == 0 {
+			return ""
+		}
+		s = list[0]
+	}
+	if len(s.Flags) > 0 || (s.VersionRemoved != nil && s.VersionRemoved != false) {
+		return ""
+	}
+	switch v := s.VersionAdded.(type) {
+	case string:
+		if v == "preview" {
+			return ""
+		}
+		return strings.TrimPrefix(v, "≤")
+	case bool:
+		if v {
+			return "1"
+		}
+	}
+
+	return ""
+}
+
+// parseBrowsers parses a browser baseline such as "chrome 100, safari 15.4" into the oldest version of each browser.
+func parseBrowsers(s string) (map[string]string, error) {
+	baseline := make(map[string]string)
+	for _, f := range strings.Split(s, ",") {
+		fs := strings.Fields(f)
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) != 2 {
+			return nil, fmt.Errorf("invalid browser baseline %q: want a browser name followed by a version", strings.TrimSpace(f))
+		}
+		if _, err := parseVersion(fs[1]); err != nil {
+			return nil, fmt.Errorf("invalid browser baseline %q: %v", strings.TrimSpace(f), err)
+		}
+		baseline[strings.ToLower(fs[0])] = fs[1]
+	}
+
+	return baseline, nil
+}
+
+// parseVersion splits the browser version v, such as "15.4", into its numeric components.
+func parseVersion(v string) ([]int, error) {
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		parts = append(parts, n)
+	}
+
+	return parts, nil
+}
+
+// versionLess reports whether the version a precedes b. Versions that do not parse sort first.
+func versionLess(a, b string) bool {
+	pa, _ := parseVersion(a)
+	pb, _ := parseVersion(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] != pb[i] {
+			return pa[i] < pb[i]
+		}
+	}
+
+	return len(pa) < len(pb)
+}
+
+// applyCompat notes in Compat which of the browsers of baseline and compatBrowsers do not support each attribute
+// of the elements of table according to compat, as in "Not supported in Firefox or Safari < 16". If exclude is set,
+// the attributes that a browser of baseline does not support are dropped instead, and described in the returned
+// list. Attributes of groups and attributes missing from compat are left alone.
+func applyCompat(table map[string]Desc, compat map[string]map[string]map[string]string, baseline map[string]string, exclude bool) (map[string]Desc, []string) {
+	floors := make(map[string]string)
+	for b, v := range compatBrowsers {
+		floors[b] = v
+	}
+	for b, v := range baseline {
+		floors[b] = v
+	}
+	var browsers []string
+	for b := range floors {
+		browsers = append(browsers, b)
+	}
+	sort.Strings(browsers)
+
+	var tags []string
+	for k := range table {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+
+	var dropped []string
+	annotated := make(map[string]Desc, len(table))
+	for _, k := range tags {
+		d := table[k]
+		var attrs []Attr
+		var unsupported []string
+		for _, a := range d.Attributes {
+			added, ok := compat[k][a.Name]
+			if !ok {
+				attrs = append(attrs, a)
+				continue
+			}
+
+			var missing []string
+			excluded := false
+			for _, b := range browsers {
+				v, ok := added[b]
+				if !ok {
+					continue
+				}
+				if v != "" && !versionLess(floors[b], v) {
+					continue
+				}
+				if _, ok := baseline[b]; ok && exclude {
+					excluded = true
+				}
+				name := browserNames[b]
+				if name == "" {
+					name = b
+				}
+				if v == "" {
+					missing = append(missing, name)
+				} else {
+					missing = append(missing, name+" < "+v)
+				}
+			}
+			if excluded {
+				unsupported = append(unsupported, a.Name)
+				continue
+			}
+			if len(missing) > 0 {
+				a.Compat = "Not supported in " + joinOr(missing) + "."
+			}
+			attrs = append(attrs, a)
+		}
+		if len(unsupported) > 0 {
+			dropped = append(dropped, fmt.Sprintf("<%s>: dropped attributes unsupported by the browser baseline %s", k, strings.Join(unsupported, ", ")))
+		}
+		d.Attributes = attrs
+		annotated[k] = d
+	}
+
+	return annotated, dropped
+}
+
+// joinOr joins s as an English list ending in "or".
+func joinOr(s []string) string {
+	if len(s) < 2 {
+		return strings.Join(s, "")
+	}
+
+	return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1]
+}
@@ -0,0 +1,77 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// compileDiag matches a diagnostic of the go command, such as "./a_elem.go:12:3: undefined: X".
+var compileDiag = regexp.MustCompile(`^(\S+\.go)(:\d+(?::\d+)?): (.*)$`)
+
+// buildPackage builds the package in dir for GOOS=js, as GopherJS and WebAssembly clients would, and records a
+// PhaseCompile Failure in res for each diagnostic, attributed to the planned element whose file it is in.
+func buildPackage(dir string, planned map[string]templElem, res *Result) {
+	owners := make(map[string]string)
+	for k := range planned {
+		owners[fileBase(k)+"_elem.go"] = k
+	}
+
+	cmd := exec.Command("go", "build", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return
+	}
+
+	n := len(res.Failures)
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		m := compileDiag.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		p := m[1]
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		res.Failures = append(res.Failures, Failure{Element: owners[filepath.Base(p)], Path: p + m[2], Phase: PhaseCompile, Err: errors.New(m[3])})
+	}
+	if len(res.Failures) == n {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		res.Failures = append(res.Failures, Failure{Path: dir, Phase: PhaseCompile, Err: errors.New(msg)})
+	}
+}
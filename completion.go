@@ -0,0 +1,194 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// flagNames returns the names of the flags of fs, sorted, and whether each takes a value.
+func flagNames(fs *flag.FlagSet) ([]string, map[string]bool) {
+	var names []string
+	valued := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+			valued[f.Name] = true
+		}
+	})
+	sort.Strings(names)
+
+	return names, valued
+}
+
+// completionTags returns the tags of the elements the flags select, offered to complete -only and -skip.
+func completionTags() []string {
+	only0, skip0 := *only, *skip
+	*only, *skip = "", ""
+	_, table, _, err := loadTable()
+	*only, *skip = only0, skip0
+	if err != nil {
+		return nil
+	}
+
+	var tags []string
+	for k := range table {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// writeBashCompletion writes to w the bash completion of the program prog. zsh uses it through bashcompinit.
+func writeBashCompletion(w io.Writer, prog string, tags []string) {
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(prog)
+	var names []string
+	for _, c := range commands {
+		names = append(names, c.name)
+		names = append(names, c.aliases...)
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} cmd="" w flags`)
+	fmt.Fprintf(w, "\tlocal commands=%q\n", strings.Join(append(names, "help"), " "))
+	fmt.Fprintln(w, `	for w in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+		case " $commands " in *" $w "*) cmd=$w; break ;; esac
+	done
+
+	case $prev in
+	-only | -skip)`)
+	fmt.Fprintf(w, "\t\tlocal pre=\"\"\n\t\t[[ $cur == *,* ]] && pre=${cur%%,*},\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -P \"$pre\" -W %q -- \"${cur##*,}\"))\n", strings.Join(tags, " "))
+	fmt.Fprintln(w, `		return ;;
+	esac
+
+	case $cmd in`)
+	for _, c := range commands {
+		fs := c.allFlags()
+		flags, _ := flagNames(fs)
+		pattern := strings.Join(append([]string{c.name}, c.aliases...), " | ")
+		if c.name == commands[0].name {
+			pattern += ` | ""`
+		}
+		fmt.Fprintf(w, "\t%s) flags=%q ;;\n", pattern, "-"+strings.Join(flags, " -"))
+	}
+	fmt.Fprintln(w, `	esac
+
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif [[ -z $cmd ]]; then
+		COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+	elif [[ $cmd == help ]]; then
+		COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+	fi
+}`)
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, prog)
+}
+
+// writeZshCompletion writes to w the zsh completion of the program prog, which loads the bash completion.
+func writeZshCompletion(w io.Writer, prog string, tags []string) {
+	fmt.Fprintf(w, "#compdef %s\n\nautoload -U +X bashcompinit && bashcompinit\n\n", prog)
+	writeBashCompletion(w, prog, tags)
+}
+
+// writeFishCompletion writes to w the fish completion of the program prog.
+func writeFishCompletion(w io.Writer, prog string, tags []string) {
+	var names []string
+	for _, c := range commands {
+		names = append(names, c.name)
+		names = append(names, c.aliases...)
+	}
+	seen := "__fish_seen_subcommand_from " + strings.Join(names, " ")
+
+	fmt.Fprintf(w, "# fish completion for %s\n\n", prog)
+	fmt.Fprintf(w, "complete -c %s -f\n", prog)
+	for _, c := range commands {
+		for _, n := range append([]string{c.name}, c.aliases...) {
+			fmt.Fprintf(w, "complete -c %s -n 'not %s' -a %s -d %s\n", prog, seen, n, fishQuote(c.summary))
+		}
+	}
+	fmt.Fprintf(w, "complete -c %s -n 'not %s' -a help -d 'print the help of a command'\n", prog, seen)
+
+	for _, c := range commands {
+		cond := "__fish_seen_subcommand_from " + strings.Join(append([]string{c.name}, c.aliases...), " ")
+		if c.name == commands[0].name {
+			cond = "not __fish_seen_subcommand_from " + strings.Join(names[1:], " ")
+		}
+		fs := c.allFlags()
+		flags, valued := flagNames(fs)
+		for _, n := range flags {
+			line := fmt.Sprintf("complete -c %s -n '%s' -o %s", prog, cond, n)
+			switch {
+			case n == "only" || n == "skip":
+				line += " -x -a " + fishQuote(strings.Join(tags, " "))
+			case valued[n]:
+				line += " -r -F"
+			}
+			usage := strings.SplitN(fs.Lookup(n).Usage, "\n", 2)[0]
+			fmt.Fprintf(w, "%s -d %s\n", line, fishQuote(strings.ReplaceAll(usage, "`", "")))
+		}
+	}
+}
+
+// fishQuote returns s single-quoted for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// runCompletion runs the completion command with the arguments args, printing the completion script of the shell
+// they name. It returns the exit status.
+func runCompletion(fs *flag.FlagSet, args []string) int {
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	prog := path.Base(os.Args[0])
+	tags := completionTags()
+	switch fs.Arg(0) {
+	case "bash":
+		writeBashCompletion(os.Stdout, prog, tags)
+	case "zsh":
+		writeZshCompletion(os.Stdout, prog, tags)
+	case "fish":
+		writeFishCompletion(os.Stdout, prog, tags)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown shell %q: want bash, zsh or fish\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	return 0
+}
@@ -0,0 +1,129 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed spec/whatwg-elements.txt
+var whatwgElementsTxt string
+
+// whatwgElements returns the elements of the HTML standard listed in spec/whatwg-elements.txt.
+func whatwgElements() []string {
+	var els []string
+	for _, l := range strings.Split(whatwgElementsTxt, "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+			els = append(els, l)
+		}
+	}
+	return els
+}
+
+// coverage cross-references the elements of the HTML standard with an element table.
+type coverage struct {
+	Standard    int      `json:"standard"`
+	Generated   []string `json:"generated"`
+	HandWritten []string `json:"handWritten"`
+	Missing     []string `json:"missing"`
+	NonStandard []string `json:"nonStandard"`
+}
+
+// checkCoverage compares table with the standard elements std.
+func checkCoverage(table map[string]Desc, std []string) *coverage {
+	c := &coverage{Standard: len(std)}
+	isStd := make(map[string]bool)
+	for _, k := range std {
+		isStd[k] = true
+		d, ok := table[k]
+		switch {
+		case !ok:
+			c.Missing = append(c.Missing, k)
+		case d.HandWritten:
+			c.HandWritten = append(c.HandWritten, k)
+		default:
+			c.Generated = append(c.Generated, k)
+		}
+	}
+	for k := range table {
+		if !isStd[k] && !strings.Contains(k, "-") {
+			c.NonStandard = append(c.NonStandard, k)
+		}
+	}
+	sort.Strings(c.NonStandard)
+
+	return c
+}
+
+// runCoverage runs the coverage command with the arguments args, reporting which standard elements the element table
+// generates, leaves to hand-written code, or lacks. It returns the exit status.
+func runCoverage(fs *flag.FlagSet, args []string) int {
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	_, table, _, err := loadTable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	c := checkCoverage(table, whatwgElements())
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(c); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitIO
+		}
+		return 0
+	}
+
+	fmt.Printf("%d of %d standard elements generated (%.0f%%)\n", len(c.Generated), c.Standard, 100*float64(len(c.Generated))/float64(c.Standard))
+	for _, l := range []struct {
+		title string
+		tags  []string
+	}{
+		{"hand-written, generated only with -all", c.HandWritten},
+		{"missing from the element table", c.Missing},
+		{"not in the standard (obsolete or non-standard)", c.NonStandard},
+	} {
+		if len(l.tags) > 0 {
+			fmt.Printf("\n%s (%d):\n  %s\n", l.title, len(l.tags), strings.Join(l.tags, " "))
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,99 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"strings"
+	"text/template"
+)
+
+// debugFile is the name of the file declaring Debug and the checks it enables.
+const debugFile = "debug.go"
+
+// generateDebug renders with base the declaration of Debug into debugFile, and writes or diffs it according to opts.
+func generateDebug(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "debug", debugFile, target, opts, res, produced)
+}
+
+// rangeChecks maps the elements whose numeric attributes Debug checks against each other to the function of debugFile
+// that checks them and the attributes it takes, all of type float64.
+var rangeChecks = map[string]struct {
+	Func  string
+	Attrs []string
+}{
+	"meter":    {"checkMeter", []string{"min", "max", "low", "high", "optimum", "value"}},
+	"progress": {"checkProgress", []string{"max", "value"}},
+}
+
+// RangeCheck returns the call of the function that checks the numeric attributes of e, see rangeChecks, or "" if
+// there is none or e lacks one of its attributes.
+func (e templElem) RangeCheck() string {
+	c, ok := rangeChecks[e.Name]
+	if !ok {
+		return ""
+	}
+	fields := make(map[string]string)
+	for _, a := range e.ElemAttrs() {
+		if a.Type == "float64" {
+			fields[a.JS] = a.Name
+		}
+	}
+
+	var args []string
+	for _, a := range c.Attrs {
+		f, ok := fields[a]
+		if !ok {
+			return ""
+		}
+		args = append(args, "rProps."+f)
+	}
+
+	return c.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Validated returns the attributes of e whose values Debug checks with their Valid method: those whose type is one of
+// the enums or a valueType with a Valid method.
+func (e templElem) Validated() []templAttr {
+	var attrs []templAttr
+	for _, a := range e.ElemAttrs() {
+		_, enum := enums[a.Type]
+		if enum || valueTypes[a.Type].Valid {
+			attrs = append(attrs, a)
+		}
+	}
+
+	return attrs
+}
+
+// Pattern returns the props field of the pattern attribute of e, which Debug checks, or "" if e has none.
+func (e templElem) Pattern() string {
+	for _, a := range e.ElemAttrs() {
+		if a.JS == "pattern" && a.Type == "string" {
+			return a.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,266 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change in a unified diff.
+const contextLines = 3
+
+type (
+	// edit is a single line of a line-based diff: ' ' for a common line, '-' for a deletion, and '+' for an insertion.
+	edit struct {
+		op   byte
+		line string
+	}
+)
+
+// unifiedDiff returns the unified diff that turns a into b, labelling the sides oldName and newName. It returns the
+// empty string when the contents are identical.
+func unifiedDiff(oldName, newName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	out := new(strings.Builder)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Walk the edit script, collecting runs of changes together with the surrounding context into hunks.
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+
+			// Stop the hunk once a run of unchanged lines is long enough to separate it from the next change.
+			run := end
+			for run < len(edits) && edits[run].op == ' ' {
+				run++
+			}
+			if run == len(edits) || run-end > 2*contextLines {
+				end += contextLines
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+
+		writeHunk(out, edits, start, end)
+		i = end
+	}
+
+	return out.String()
+}
+
+// writeHunk writes the edits in [start, end) as a single hunk with its line-range header.
+func writeHunk(out *strings.Builder, edits []edit, start, end int) {
+	oldLine, newLine := 1, 1
+	for _, e := range edits[:start] {
+		if e.op != '+' {
+			oldLine++
+		}
+		if e.op != '-' {
+			newLine++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, e := range edits[start:end] {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range is addressed by the line before it.
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, e := range edits[start:end] {
+		fmt.Fprintf(out, "%c%s\n", e.op, e.line)
+	}
+}
+
+// diffLines computes a minimal line edit script from a to b with the linear-space variant of Myers' O(ND) algorithm,
+// so that diffing a large generated file needs memory proportional to its length rather than to its square.
+func diffLines(a, b []string) []edit {
+	return appendDiff(nil, a, b)
+}
+
+// appendDiff appends to edits a minimal edit script from a to b. The common prefix and suffix are kept as they are;
+// the rest is split at the middle snake found by bisect and each half diffed in turn.
+func appendDiff(edits []edit, a, b []string) []edit {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		edits = append(edits, edit{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	suffix := a[len(a)-n:]
+	a, b = a[:len(a)-n], b[:len(b)-n]
+
+	x, y, ok := bisect(a, b)
+	switch {
+	case ok:
+		edits = appendDiff(edits, a[:x], b[:y])
+		edits = appendDiff(edits, a[x:], b[y:])
+	default:
+		for _, l := range a {
+			edits = append(edits, edit{'-', l})
+		}
+		for _, l := range b {
+			edits = append(edits, edit{'+', l})
+		}
+	}
+	for _, l := range suffix {
+		edits = append(edits, edit{' ', l})
+	}
+
+	return edits
+}
+
+// bisect finds the middle snake of a shortest edit script from a to b, which neither start nor end with the same
+// line, by following the furthest reaching paths from both corners until they overlap. It returns the point (x, y)
+// that splits the script into two of about half its length, or false if a or b is empty and there is nothing to
+// split.
+func bisect(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	// v1[off+k] and v2[off+k] hold how far along a the furthest reaching forward and reverse paths in diagonal k go.
+	maxD := (n + m + 1) / 2
+	off := maxD
+	v1 := make([]int, 2*maxD+2)
+	v2 := make([]int, 2*maxD+2)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[off+1], v2[off+1] = 0, 0
+
+	delta := n - m
+	// The paths overlap first on the forward pass if delta is odd, and on the reverse pass otherwise.
+	front := delta%2 != 0
+	// Diagonals that have run off an edge of the grid need not be followed further.
+	var k1start, k1end, k2start, k2end int
+	for d := 0; d < maxD; d++ {
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			k1off := off + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[k1off-1] < v1[k1off+1]) {
+				x1 = v1[k1off+1]
+			} else {
+				x1 = v1[k1off-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1off] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				k2off := off + delta - k1
+				if k2off >= 0 && k2off < len(v2) && v2[k2off] != -1 && x1 >= n-v2[k2off] {
+					return x1, y1, true
+				}
+			}
+		}
+
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			k2off := off + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[k2off-1] < v2[k2off+1]) {
+				x2 = v2[k2off+1]
+			} else {
+				x2 = v2[k2off-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2off] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				k1off := off + delta - k2
+				if k1off >= 0 && k1off < len(v1) && v1[k1off] != -1 {
+					x1 := v1[k1off]
+					if x1 >= n-x2 {
+						return x1, x1 - (k1off - off), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// splitLines splits data into lines without their terminating newlines.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
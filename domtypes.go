@@ -0,0 +1,369 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+// domTypes maps the elements to the types that honnef.co/go/js/dom wraps their DOM nodes in, by tag name. Elements
+// missing from the map, including custom elements, are wrapped in a *dom.BasicHTMLElement.
+var domTypes = map[string]string{
+	"a":          "HTMLAnchorElement",
+	"applet":     "HTMLUnknownElement",
+	"area":       "HTMLAreaElement",
+	"audio":      "HTMLAudioElement",
+	"base":       "HTMLBaseElement",
+	"blockquote": "HTMLQuoteElement",
+	"body":       "HTMLBodyElement",
+	"br":         "HTMLBRElement",
+	"button":     "HTMLButtonElement",
+	"canvas":     "HTMLCanvasElement",
+	"caption":    "HTMLTableCaptionElement",
+	"col":        "HTMLTableColElement",
+	"colgroup":   "HTMLTableColElement",
+	"data":       "HTMLDataElement",
+	"datalist":   "HTMLDataListElement",
+	"del":        "HTMLModElement",
+	"div":        "HTMLDivElement",
+	"dl":         "HTMLDListElement",
+	"embed":      "HTMLEmbedElement",
+	"fieldset":   "HTMLFieldSetElement",
+	"form":       "HTMLFormElement",
+	"h1":         "HTMLHeadingElement",
+	"h2":         "HTMLHeadingElement",
+	"h3":         "HTMLHeadingElement",
+	"h4":         "HTMLHeadingElement",
+	"h5":         "HTMLHeadingElement",
+	"h6":         "HTMLHeadingElement",
+	"head":       "HTMLHeadElement",
+	"hr":         "HTMLHRElement",
+	"html":       "HTMLHtmlElement",
+	"iframe":     "HTMLIFrameElement",
+	"img":        "HTMLImageElement",
+	"input":      "HTMLInputElement",
+	"ins":        "HTMLModElement",
+	"label":      "HTMLLabelElement",
+	"legend":     "HTMLLegendElement",
+	"li":         "HTMLLIElement",
+	"link":       "HTMLLinkElement",
+	"map":        "HTMLMapElement",
+	"menu":       "HTMLMenuElement",
+	"meta":       "HTMLMetaElement",
+	"meter":      "HTMLMeterElement",
+	"object":     "HTMLObjectElement",
+	"ol":         "HTMLOListElement",
+	"optgroup":   "HTMLOptGroupElement",
+	"option":     "HTMLOptionElement",
+	"output":     "HTMLOutputElement",
+	"p":          "HTMLParagraphElement",
+	"param":      "HTMLParamElement",
+	"pre":        "HTMLPreElement",
+	"progress":   "HTMLProgressElement",
+	"q":          "HTMLQuoteElement",
+	"script":     "HTMLScriptElement",
+	"select":     "HTMLSelectElement",
+	"source":     "HTMLSourceElement",
+	"span":       "HTMLSpanElement",
+	"style":      "HTMLStyleElement",
+	"table":      "HTMLTableElement",
+	"tbody":      "HTMLTableSectionElement",
+	"td":         "HTMLTableCellElement",
+	"textarea":   "HTMLTextAreaElement",
+	"tfoot":      "HTMLTableSectionElement",
+	"th":         "HTMLTableCellElement",
+	"thead":      "HTMLTableSectionElement",
+	"time":       "HTMLTimeElement",
+	"title":      "HTMLTitleElement",
+	"tr":         "HTMLTableRowElement",
+	"track":      "HTMLTrackElement",
+	"ul":         "HTMLUListElement",
+	"video":      "HTMLVideoElement",
+}
+
+// domType returns the type that honnef.co/go/js/dom wraps the DOM node of the element k in.
+func domType(k string) string {
+	if t, ok := domTypes[k]; ok {
+		return t
+	}
+
+	return "BasicHTMLElement"
+}
+
+// refMethod is a method of the Ref type generated for an element with -refs, forwarding to the element's DOM node
+// r.node. Sig is the signature after the name, with named results so that the stub can return their zero values,
+// and Body the statements, which must handle an unmounted element, whose node is nil.
+type refMethod struct {
+	Name, Doc, Sig, Body string
+}
+
+// mediaMethods are the methods of the Ref types of <audio> and <video>, controlling the playback that props alone
+// cannot.
+var mediaMethods = []refMethod{
+	{
+		Name: "Play",
+		Doc:  "starts or resumes playback, ignoring the promise the browser returns.",
+		Sig:  "()",
+		Body: "if r.node != nil {\n\tr.node.Call(\"play\")\n}",
+	},
+	{
+		Name: "Pause",
+		Doc:  "pauses playback.",
+		Sig:  "()",
+		Body: "if r.node != nil {\n\tr.node.Call(\"pause\")\n}",
+	},
+	{
+		Name: "CurrentTime",
+		Doc:  "returns the playback position in seconds.",
+		Sig:  "() (seconds float64)",
+		Body: "if r.node != nil {\n\tseconds = r.node.Get(\"currentTime\").Float()\n}\nreturn",
+	},
+	{
+		Name: "SetCurrentTime",
+		Doc:  "seeks to the position seconds.",
+		Sig:  "(seconds float64)",
+		Body: "if r.node != nil {\n\tr.node.Set(\"currentTime\", seconds)\n}",
+	},
+	{
+		Name: "Volume",
+		Doc:  "returns the volume, from 0 to 1.",
+		Sig:  "() (volume float64)",
+		Body: "if r.node != nil {\n\tvolume = r.node.Get(\"volume\").Float()\n}\nreturn",
+	},
+	{
+		Name: "SetVolume",
+		Doc:  "sets the volume, from 0 to 1.",
+		Sig:  "(volume float64)",
+		Body: "if r.node != nil {\n\tr.node.Set(\"volume\", volume)\n}",
+	},
+}
+
+// dialogMethods are the methods of the Ref type of <dialog>, which is opened and closed through them rather than its
+// open attribute.
+var dialogMethods = []refMethod{
+	{
+		Name: "Show",
+		Doc:  "opens the dialog without making the rest of the page inert.",
+		Sig:  "()",
+		Body: "if r.node != nil {\n\tr.node.Call(\"show\")\n}",
+	},
+	{
+		Name: "ShowModal",
+		Doc:  "opens the dialog as a modal, over the rest of the page, which becomes inert.",
+		Sig:  "()",
+		Body: "if r.node != nil {\n\tr.node.Call(\"showModal\")\n}",
+	},
+	{
+		Name: "Close",
+		Doc:  "closes the dialog, setting its return value to returnValue and firing its close event.",
+		Sig:  "(returnValue string)",
+		Body: "if r.node != nil {\n\tr.node.Call(\"close\", returnValue)\n}",
+	},
+	{
+		Name: "ReturnValue",
+		Doc:  "returns the value the dialog was last closed with.",
+		Sig:  "() (returnValue string)",
+		Body: "if r.node != nil {\n\treturnValue = r.node.Get(\"returnValue\").String()\n}\nreturn",
+	},
+}
+
+// refMethods maps the elements whose DOM nodes have an imperative API to the methods of their Ref types.
+var refMethods = map[string][]refMethod{
+	"audio":  mediaMethods,
+	"dialog": dialogMethods,
+	"video":  mediaMethods,
+}
+
+// eventHandlers are the event handler interfaces that the generated code declares in eventsFile, by type name, with
+// the event each handles. An attribute of one of these types, such as onClose, holds a value whose method of the same
+// name React calls with the event.
+var eventHandlers = map[string]string{
+	"OnBeforeToggle": "beforetoggle",
+	"OnClose":        "close",
+	"OnToggle":       "toggle",
+}
+
+// jsBackedTypes are the pointer types of attributes whose values wrap a *js.Object, such as the *CSS of style. Copying
+// one copies the reference to the JavaScript object, not the object, so Clone shares them.
+var jsBackedTypes = map[string]bool{
+	"*CSS":                true,
+	"*DangerousInnerHTML": true,
+	"*InlineStyle":        true,
+}
+
+// voidElements are the elements that cannot have children.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "basefont": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// a11yUntested are the elements that render nothing to check for accessibility, or only make sense at the top of a
+// document, and are left out of the generated accessibility test.
+var a11yUntested = map[string]bool{
+	"base": true, "basefont": true, "body": true, "head": true, "html": true, "link": true, "meta": true,
+	"noscript": true, "param": true, "script": true, "source": true, "style": true, "template": true, "title": true,
+	"track": true,
+}
+
+// a11yNames are the attributes that give an element an accessible name or text alternative, set to representative
+// values in the generated accessibility test.
+var a11yNames = map[string]bool{
+	"alt": true, "label": true, "title": true,
+}
+
+// controlledProps maps the attributes that make a form control controlled, holding the value React renders, to the
+// React props that only set its initial value and leave it uncontrolled.
+var controlledProps = map[string]string{
+	"checked": "defaultChecked",
+	"value":   "defaultValue",
+}
+
+// elementCategories maps the elements to the section of the HTML standard that defines them, such as "sections" or
+// "forms", or to "obsolete". Elements missing from the map, including custom elements, are in the "custom" category.
+var elementCategories = map[string]string{
+	"a":               "text-level",
+	"abbr":            "text-level",
+	"acronym":         "obsolete",
+	"address":         "sections",
+	"applet":          "obsolete",
+	"area":            "embedded",
+	"article":         "sections",
+	"aside":           "sections",
+	"audio":           "embedded",
+	"b":               "text-level",
+	"base":            "metadata",
+	"basefont":        "obsolete",
+	"bdi":             "text-level",
+	"bdo":             "text-level",
+	"blockquote":      "grouping",
+	"body":            "sections",
+	"br":              "text-level",
+	"button":          "forms",
+	"canvas":          "embedded",
+	"caption":         "tabular",
+	"cite":            "text-level",
+	"code":            "text-level",
+	"col":             "tabular",
+	"colgroup":        "tabular",
+	"data":            "text-level",
+	"datalist":        "forms",
+	"dd":              "grouping",
+	"del":             "edits",
+	"details":         "interactive",
+	"dfn":             "text-level",
+	"dialog":          "interactive",
+	"div":             "grouping",
+	"dl":              "grouping",
+	"dt":              "grouping",
+	"em":              "text-level",
+	"embed":           "embedded",
+	"fieldset":        "forms",
+	"figcaption":      "grouping",
+	"figure":          "grouping",
+	"footer":          "sections",
+	"form":            "forms",
+	"h1":              "sections",
+	"h2":              "sections",
+	"h3":              "sections",
+	"h4":              "sections",
+	"h5":              "sections",
+	"h6":              "sections",
+	"head":            "metadata",
+	"header":          "sections",
+	"hgroup":          "sections",
+	"hr":              "grouping",
+	"html":            "document",
+	"i":               "text-level",
+	"iframe":          "embedded",
+	"img":             "embedded",
+	"input":           "forms",
+	"ins":             "edits",
+	"kbd":             "text-level",
+	"label":           "forms",
+	"legend":          "forms",
+	"li":              "grouping",
+	"link":            "metadata",
+	"main":            "sections",
+	"map":             "embedded",
+	"mark":            "text-level",
+	"menu":            "grouping",
+	"meta":            "metadata",
+	"meter":           "forms",
+	"nav":             "sections",
+	"noscript":        "scripting",
+	"object":          "embedded",
+	"ol":              "grouping",
+	"optgroup":        "forms",
+	"option":          "forms",
+	"output":          "forms",
+	"p":               "grouping",
+	"param":           "obsolete",
+	"picture":         "embedded",
+	"pre":             "grouping",
+	"progress":        "forms",
+	"q":               "text-level",
+	"rp":              "text-level",
+	"rt":              "text-level",
+	"rtc":             "obsolete",
+	"ruby":            "text-level",
+	"s":               "text-level",
+	"samp":            "text-level",
+	"script":          "scripting",
+	"search":          "sections",
+	"section":         "sections",
+	"select":          "forms",
+	"selectedcontent": "forms",
+	"slot":            "scripting",
+	"small":           "text-level",
+	"source":          "embedded",
+	"span":            "text-level",
+	"strong":          "text-level",
+	"style":           "metadata",
+	"sub":             "text-level",
+	"summary":         "interactive",
+	"sup":             "text-level",
+	"table":           "tabular",
+	"tbody":           "tabular",
+	"td":              "tabular",
+	"template":        "scripting",
+	"textarea":        "forms",
+	"tfoot":           "tabular",
+	"th":              "tabular",
+	"thead":           "tabular",
+	"time":            "text-level",
+	"title":           "metadata",
+	"tr":              "tabular",
+	"track":           "embedded",
+	"u":               "text-level",
+	"ul":              "grouping",
+	"var":             "text-level",
+	"video":           "embedded",
+	"wbr":             "text-level",
+}
+
+// elementCategory returns the category of the element k.
+func elementCategory(k string) string {
+	if c, ok := elementCategories[k]; ok {
+		return c
+	}
+
+	return "custom"
+}
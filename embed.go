@@ -0,0 +1,95 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"embed"
+)
+
+// The templates and the element catalog are embedded in the binary, which therefore needs no other files at run time.
+// They are laid out as follows:
+//
+//	templates/primary.tmpl     <tag>_elem.go, the wrapper of an element
+//	templates/test.tmpl        <tag>_elem_test.go, the test of an element's wrapper
+//	templates/stub.tmpl        <tag>_elem_stub.go, the placeholder of an element outside of js builds (-stubs)
+//	templates/groups.tmpl      attrgroups.go, the structs of the attribute groups
+//	templates/options.tmpl     options.go, the options shared by the Opt constructors of all elements
+//	templates/classes.tmpl     classes.go, Classes and ClassMap composing class names
+//	templates/registry.tmpl    registry.go, ElementsByTag and CreateByTag
+//	templates/shallow.tmpl     shallow.go, Shallow and FindShallow
+//	templates/meta.tmpl        elements_meta_gen.go, ElementsMeta describing every element
+//	templates/events.tmpl      events.go, sameHandler and the event handler interfaces of the attributes, such as OnClose
+//	templates/style.tmpl       style.go, InlineStyle, the typed inline style of the elements (-typed-style)
+//	templates/constraint.tmpl  constraint.go, Bound, the type of the min, max and step attributes
+//	templates/enums.tmpl       enums.go, the enumerated types of attribute values, such as ReferrerPolicy
+//	templates/autofill.tmpl    autocomplete.go, AutoComplete and Autofill, the autofill hints of form controls
+//	templates/accept.tmpl      accept.go, AcceptList, the type of the accept attribute of file inputs
+//	templates/allow.tmpl       allow.go, PermissionsPolicy, the features granted by the allow attribute of iframes
+//	templates/sandbox.tmpl     sandbox.go, Sandbox and SandboxPolicy, the restrictions lifted from sandboxed iframes
+//	templates/srcset.tmpl      srcset.go, SrcSet and Sizes, the responsive image candidates of img and source
+//	templates/datetime.tmpl    datetime.go, DateTime, the datetime attribute of time, ins and del
+//	templates/dimension.tmpl   dimension.go, Dimension, the width and height of images, videos and embedded content
+//	templates/color.tmpl       color.go, Color, the legacy color attributes and the colors of InlineStyle
+//	templates/url.tmpl         url.go, URL, the attributes holding a URL, such as href and src, checked by Debug
+//	templates/metahelpers.tmpl meta_helpers.go, MetaCharset, MetaViewport and the other <meta> constructors
+//	templates/linkhelpers.tmpl link_helpers.go, LinkStylesheet, LinkPreload and the other <link> constructors
+//	templates/tmplhelpers.tmpl template_helpers.go, TemplateHTML and ShadowRoot providing the content of <template>
+//	templates/headbuilder.tmpl head_builder.go, HeadBuilder assembling the boilerplate children of <head>
+//	templates/socialmeta.tmpl  meta_social.go, MetaOGTitle, MetaTwitterCard and the other social <meta> constructors
+//	templates/debug.tmpl       debug.go, Debug and the checks of the props it enables
+//	templates/roles.tmpl       roles.go, Role, the WAI-ARIA roles by category, which Debug checks
+//	templates/a11ylint.tmpl    a11ylint.go, the accessibility checks of the constructors (-a11y-lint)
+//	templates/lintmode.tmpl    a11ylint_on.go and a11ylint_off.go, a11yLint enabling the checks with the a11ylint tag
+//	templates/formvalues.tmpl  formvalues.go, FormValues and FormJSON collecting the values of the form controls
+//	templates/tabletest.tmpl   elements_test.go, the table-driven test of every element (-tests)
+//	templates/proptest.tmpl    props_test.go, the tests that the props of every element reach JavaScript
+//	templates/a11ytest.tmpl    a11y_test.go, the accessibility test of every element (-a11y-tests)
+//	spec/catalog.json          the element catalog: a spec (see Spec) describing every element and attribute group
+//	spec/whatwg-elements.txt   the elements of the HTML standard, against which coverage checks the catalog
+//
+// The element templates are executed with a templElem, the others with a templTarget or a struct embedding one. All
+// of them may call the builtinFuncs.
+//
+// The catalog carries a version that is bumped whenever elements or attributes are added, removed or changed.
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templateNames lists the templates in templateFS, by file name without the .tmpl extension.
+var templateNames = []string{"primary", "test", "stub", "groups", "options", "classes", "registry", "tabletest", "proptest", "shallow", "a11ytest", "meta", "events", "style", "formvalues", "constraint", "debug", "accept", "sandbox", "allow", "enums", "srcset", "datetime", "dimension", "color", "metahelpers", "linkhelpers", "headbuilder", "socialmeta", "url", "autofill", "tmplhelpers", "roles", "a11ylint", "lintmode"}
+
+//go:embed spec/catalog.json
+var catalogJSON []byte
+
+// catalog is the embedded element catalog.
+var catalog = func() *Spec {
+	spec, err := decodeSpec(bytes.NewReader(catalogJSON))
+	if err != nil {
+		panic("spec/catalog.json: " + err.Error())
+	}
+	spec.Elements = withGlobalGroups(spec.Elements, spec.GlobalGroups, spec.Groups)
+	return spec
+}()
@@ -0,0 +1,359 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// enumsFile is the name of the file declaring the enumerated types of attribute values.
+const enumsFile = "enums.go"
+
+type (
+	// enum is an enumerated type of attribute values, a string type whose constants are the keywords the attributes
+	// accept. Doc completes the sentence "<type> is". List reports that the attributes take a space-separated list of
+	// the keywords rather than a single one.
+	enum struct {
+		Doc    string
+		List   bool
+		Values []enumValue
+	}
+
+	// enumValue is a keyword of an enum. The constant of the keyword is named after the enum followed by Name or, if
+	// Name is empty, the keyword as the default Namer renders it.
+	enumValue struct {
+		Name, Value string
+	}
+
+	// templEnums is the data of the template that declares the enums. Lists reports that some of them are lists.
+	templEnums struct {
+		templTarget
+
+		Enums []templEnum
+		Lists bool
+	}
+
+	// templEnum is an enum as the template declares it, with the names of its constants resolved and its doc comment
+	// wrapped.
+	templEnum struct {
+		Type, Doc string
+		List      bool
+		Values    []enumValue
+	}
+)
+
+// enums maps the names of the enums to their declarations. The attributes of the element catalog refer to them by
+// type name.
+var enums = map[string]enum{
+	"Charset": {
+		Doc: "the value of the charset attribute of a meta element: the character encoding of the document. Only " +
+			"CharsetUTF8 conforms to HTML; the others are for legacy documents",
+		Values: []enumValue{
+			{Name: "UTF8", Value: "utf-8"},
+			{Name: "UTF16LE", Value: "utf-16le"},
+			{Name: "UTF16BE", Value: "utf-16be"},
+			{Name: "ISO88591", Value: "iso-8859-1"},
+			{Name: "Windows1252", Value: "windows-1252"},
+			{Name: "ShiftJIS", Value: "shift_jis"},
+			{Name: "EUCJP", Value: "euc-jp"},
+			{Name: "EUCKR", Value: "euc-kr"},
+			{Name: "GB18030", Value: "gb18030"},
+			{Name: "Big5", Value: "big5"},
+		},
+	},
+	"CrossOrigin": {
+		Doc: "the value of the crossorigin attribute: whether the requests for the resource use CORS and, if so, " +
+			"send credentials",
+		Values: []enumValue{
+			{Value: "anonymous"},
+			{Value: "use-credentials"},
+		},
+	},
+	"Decoding": {
+		Doc: "the value of the decoding attribute of an image: whether the browser may decode it after presenting " +
+			"the other content, rather than together with it",
+		Values: []enumValue{
+			{Value: "async"},
+			{Value: "auto"},
+			{Value: "sync"},
+		},
+	},
+	"EnterKeyHint": {
+		Doc: "the value of the enterkeyhint attribute of an editable element: the label or icon of the enter key of " +
+			"the virtual keyboard, after the action it performs",
+		Values: []enumValue{
+			{Value: "done"},
+			{Value: "enter"},
+			{Value: "go"},
+			{Value: "next"},
+			{Value: "previous"},
+			{Value: "search"},
+			{Value: "send"},
+		},
+	},
+	"FetchPriority": {
+		Doc: "the value of the fetchpriority attribute: the priority of the request for the resource relative to " +
+			"the other resources of the same kind",
+		Values: []enumValue{
+			{Value: "auto"},
+			{Value: "high"},
+			{Value: "low"},
+		},
+	},
+	"HTTPEquiv": {
+		Doc: "the value of the http-equiv attribute of a meta element: the pragma that its content applies, as the " +
+			"HTTP header of the same name would",
+		Values: []enumValue{
+			{Value: "content-language"},
+			{Value: "content-security-policy"},
+			{Value: "content-type"},
+			{Value: "default-style"},
+			{Value: "refresh"},
+			{Value: "set-cookie"},
+			{Name: "XUACompatible", Value: "x-ua-compatible"},
+		},
+	},
+	"Hidden": {
+		Doc: "the value of the hidden attribute: whether the element is hidden, as not yet or no longer relevant. An " +
+			"element hidden until found is revealed when the user finds its text by searching the page or follows a " +
+			"link to a fragment in it, firing beforematch first. React renders the keyword only from version 19; " +
+			"earlier versions render it as plain hidden",
+		Values: []enumValue{
+			{Name: "Always", Value: "hidden"},
+			{Value: "until-found"},
+		},
+	},
+	"InputMode": {
+		Doc: "the value of the inputmode attribute of an editable element: the kind of data entered, which selects " +
+			"the virtual keyboard. The type of an input sets its keyboard already",
+		Values: []enumValue{
+			{Value: "decimal"},
+			{Value: "email"},
+			{Value: "none"},
+			{Value: "numeric"},
+			{Value: "search"},
+			{Value: "tel"},
+			{Value: "text"},
+			{Value: "url"},
+		},
+	},
+	"LinkAs": {
+		Doc: "the value of the as attribute of a link element that preloads a resource: the kind of resource, which " +
+			"sets the priority and the headers of the request",
+		Values: []enumValue{
+			{Value: "audio"},
+			{Value: "document"},
+			{Value: "embed"},
+			{Value: "fetch"},
+			{Value: "font"},
+			{Value: "image"},
+			{Value: "object"},
+			{Value: "script"},
+			{Value: "style"},
+			{Value: "track"},
+			{Value: "video"},
+			{Value: "worker"},
+		},
+	},
+	"LinkRel": {
+		Doc: "the value of the rel attribute of a link element: the space-separated relationships of the linked " +
+			"resource to the document. Combine the keywords with the + operator and a space, as in " +
+			`LinkRelAlternate + " " + LinkRelStylesheet`,
+		List: true,
+		Values: []enumValue{
+			{Value: "alternate"},
+			{Value: "author"},
+			{Value: "canonical"},
+			{Name: "DNSPrefetch", Value: "dns-prefetch"},
+			{Value: "help"},
+			{Value: "icon"},
+			{Value: "license"},
+			{Value: "manifest"},
+			{Name: "ModulePreload", Value: "modulepreload"},
+			{Value: "next"},
+			{Value: "pingback"},
+			{Value: "preconnect"},
+			{Value: "prefetch"},
+			{Value: "preload"},
+			{Value: "prev"},
+			{Value: "search"},
+			{Value: "stylesheet"},
+		},
+	},
+	"ListType": {
+		Doc: "the value of the type attribute of an ordered list: the kind of marker that numbers its items",
+		Values: []enumValue{
+			{Name: "Decimal", Value: "1"},
+			{Name: "LowerAlpha", Value: "a"},
+			{Name: "UpperAlpha", Value: "A"},
+			{Name: "LowerRoman", Value: "i"},
+			{Name: "UpperRoman", Value: "I"},
+		},
+	},
+	"Loading": {
+		Doc: "the value of the loading attribute of an image or iframe: whether the browser loads it at once or " +
+			"defers loading it until it nears the viewport",
+		Values: []enumValue{
+			{Value: "eager"},
+			{Value: "lazy"},
+		},
+	},
+	"Popover": {
+		Doc: "the value of the popover attribute: how the element behaves as a popover, hidden until shown. An auto " +
+			"popover closes the other auto popovers and light-dismisses, closing on a click outside it or the Escape " +
+			"key; a manual popover closes only when told to",
+		Values: []enumValue{
+			{Value: "auto"},
+			{Value: "manual"},
+		},
+	},
+	"PopoverTargetAction": {
+		Doc: "the value of the popovertargetaction attribute of a button: what it does to the popover named by its " +
+			"popovertarget when activated. The zero PopoverTargetAction toggles it",
+		Values: []enumValue{
+			{Value: "hide"},
+			{Value: "show"},
+			{Value: "toggle"},
+		},
+	},
+	"Preload": {
+		Doc: "the value of the preload attribute of a media element: how much of the media the browser should load " +
+			"before it is played",
+		Values: []enumValue{
+			{Value: "none"},
+			{Value: "metadata"},
+			{Value: "auto"},
+		},
+	},
+	"ReferrerPolicy": {
+		Doc: "the value of the referrerpolicy attribute: how much of the document's URL is sent as the referrer of the " +
+			"requests for the resource",
+		Values: []enumValue{
+			{Value: "no-referrer"},
+			{Value: "no-referrer-when-downgrade"},
+			{Value: "origin"},
+			{Value: "origin-when-cross-origin"},
+			{Value: "same-origin"},
+			{Value: "strict-origin"},
+			{Value: "strict-origin-when-cross-origin"},
+			{Value: "unsafe-url"},
+		},
+	},
+	"ShadowRootMode": {
+		Doc: "the value of the shadowrootmode attribute of a template: the mode of the shadow root that the template " +
+			"declares for its parent element, whose shadow root is exposed to scripts only if it is open",
+		Values: []enumValue{
+			{Value: "closed"},
+			{Value: "open"},
+		},
+	},
+	"TrackKind": {
+		Doc: "the value of the kind attribute of a track element: how the text track is meant to be used",
+		Values: []enumValue{
+			{Value: "subtitles"},
+			{Value: "captions"},
+			{Value: "descriptions"},
+			{Value: "chapters"},
+			{Value: "metadata"},
+		},
+	},
+	"Translate": {
+		Doc: "the value of the translate attribute: whether the text and the translatable attributes of the " +
+			"element and its descendants should be translated when the page is localized. The zero Translate " +
+			"inherits the setting of the parent",
+		Values: []enumValue{
+			{Value: "no"},
+			{Value: "yes"},
+		},
+	},
+}
+
+// newTemplEnum resolves the names of the constants of the enum t.
+func newTemplEnum(t string) templEnum {
+	e := templEnum{Type: t, Doc: wrapComment(t + " is " + enums[t].Doc + "."), List: enums[t].List}
+	for _, v := range enums[t].Values {
+		if v.Name == "" {
+			v.Name = defaultNamer.Name(v.Value)
+		}
+		e.Values = append(e.Values, enumValue{Name: t + v.Name, Value: v.Value})
+	}
+
+	return e
+}
+
+// wrapComment returns the text s as the lines of a Go comment no longer than 120 columns.
+func wrapComment(s string) string {
+	var lines []string
+	line := "//"
+	for _, w := range strings.Fields(s) {
+		if len(line)+1+len(w) > 120 && line != "//" {
+			lines = append(lines, line)
+			line = "//"
+		}
+		line += " " + w
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
+
+// enumDecls returns the identifiers declared in enumsFile when every enum is used.
+func enumDecls() []string {
+	var ids []string
+	for t := range enums {
+		ids = append(ids, t)
+		for _, v := range newTemplEnum(t).Values {
+			ids = append(ids, v.Name)
+		}
+	}
+
+	return ids
+}
+
+// generateEnums renders with base the declarations of the enums that the attributes of the planned elements use, if
+// any, into enumsFile, and writes or diffs it according to opts.
+func generateEnums(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	used := make(map[string]bool)
+	for _, k := range tags {
+		for _, a := range planned[k].ElemAttrs() {
+			if _, ok := enums[a.Type]; ok {
+				used[a.Type] = true
+			}
+		}
+	}
+	if len(used) == 0 {
+		return
+	}
+
+	data := templEnums{templTarget: target}
+	for t := range used {
+		data.Enums = append(data.Enums, newTemplEnum(t))
+		data.Lists = data.Lists || enums[t].List
+	}
+	sort.Slice(data.Enums, func(i, j int) bool { return data.Enums[i].Type < data.Enums[j].Type })
+
+	generateShared(base, "enums", enumsFile, data, opts, res, produced)
+}
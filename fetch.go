@@ -0,0 +1,208 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// cacheEntry is the metadata kept next to a cached response body: the URL it was fetched from, the validators
+	// the server sent with it, and when it was last confirmed current.
+	cacheEntry struct {
+		URL          string    `json:"url"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified string    `json:"lastModified,omitempty"`
+		Fetched      time.Time `json:"fetched"`
+	}
+
+	// fetched is a response that replaces the cached one.
+	fetched struct {
+		body  []byte
+		entry cacheEntry
+	}
+)
+
+var (
+	// httpClient fetches remote inputs through the proxy named by HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	httpClient = &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		Timeout:   2 * time.Minute,
+	}
+
+	// lastRequest is when the last request went out, so that consecutive requests are spaced by -rate-limit.
+	lastRequest   time.Time
+	lastRequestMu sync.Mutex
+)
+
+// isURL reports whether p names a remote input rather than a file.
+func isURL(p string) bool {
+	return strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://")
+}
+
+// readInput returns the contents of the file p or, if p is a URL, of the resource it names, fetched through the
+// cache.
+func readInput(p string) ([]byte, error) {
+	if isURL(p) {
+		return fetch(p)
+	}
+	return os.ReadFile(p)
+}
+
+// fetch returns the body of the resource at url. Responses are cached in -cache-dir and reused without a request
+// for -cache-ttl, after which they are revalidated with their ETag or modification time. With -offline only the
+// cache is used. A stale cached body is returned, with a warning, if the server cannot be reached.
+func fetch(url string) ([]byte, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(url))
+	base := filepath.Join(dir, hex.EncodeToString(sum[:]))
+
+	var entry cacheEntry
+	body, err := os.ReadFile(base + ".body")
+	cached := err == nil
+	if cached {
+		if b, err := os.ReadFile(base + ".json"); err != nil || json.Unmarshal(b, &entry) != nil {
+			cached, entry = false, cacheEntry{}
+		}
+	}
+
+	if *offline {
+		if !cached {
+			return nil, fmt.Errorf("%s: not in the cache and -offline is set", url)
+		}
+		return body, nil
+	}
+	if cached && time.Since(entry.Fetched) < *cacheTTL {
+		return body, nil
+	}
+
+	fresh, err := request(url, entry, cached)
+	if err != nil {
+		if cached {
+			logger.Warn("using the cached copy", "url", url, "fetched", entry.Fetched, "err", err)
+			return body, nil
+		}
+		return nil, err
+	}
+	if fresh != nil {
+		body = fresh.body
+		entry = fresh.entry
+	}
+	entry.URL, entry.Fetched = url, time.Now()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	meta, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if fresh != nil {
+		if err := writeFile(base+".body", body); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeFile(base+".json", meta); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// request fetches url after waiting out -rate-limit, conditionally on the validators of entry if cached. It returns
+// nil if the server reports that the cached body is still current.
+func request(url string, entry cacheEntry, cached bool) (*fetched, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "elemental/"+version)
+	if cached {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	lastRequestMu.Lock()
+	if wait := *rateLimit - time.Since(lastRequest); wait > 0 {
+		time.Sleep(wait)
+	}
+	lastRequest = time.Now()
+	lastRequestMu.Unlock()
+
+	logger.Debug("fetching", "url", url)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+
+	return &fetched{
+		body:  body,
+		entry: cacheEntry{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")},
+	}, nil
+}
+
+// cacheDir returns the directory of the cached responses: -cache-dir or, by default, elemental in the user's cache
+// directory.
+func cacheDir() (string, error) {
+	if *cacheDirectory != "" {
+		return *cacheDirectory, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no cache directory (set -cache-dir): %v", err)
+	}
+
+	return filepath.Join(dir, "elemental"), nil
+}
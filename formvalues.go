@@ -0,0 +1,102 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// formValuesFile is the name of the file declaring FormValues and FormJSON, which collect the values that the form
+// controls in a tree of generated elements would submit.
+const formValuesFile = "formvalues.go"
+
+// formControls are the elements whose name and value a form submits.
+var formControls = map[string]bool{
+	"input": true, "select": true, "textarea": true,
+}
+
+type (
+	// templFormValues is the data of the template that declares FormValues.
+	templFormValues struct {
+		templTarget
+
+		Controls []templFormControl
+	}
+
+	// templFormControl is a form control among the generated elements. Name and Value are the props fields holding
+	// its name and current value; the other fields are empty unless the element has the attribute: DefaultValue and
+	// DefaultChecked hold the initial value of an uncontrolled control, and Checked and Type make checkboxes and
+	// radio buttons submit their value only when checked.
+	templFormControl struct {
+		Props, Name, Value, DefaultValue, Checked, DefaultChecked, Disabled, Type string
+	}
+)
+
+// newTemplFormControl returns the form control e, or false if e is not one or lacks a string name or value.
+func newTemplFormControl(e templElem) (templFormControl, bool) {
+	fields := make(map[string]templAttr)
+	for _, a := range e.ElemAttrs() {
+		fields[a.JS] = a
+	}
+	field := func(name, typ string) string {
+		if a, ok := fields[name]; ok && a.Type == typ {
+			return a.Name
+		}
+
+		return ""
+	}
+
+	c := templFormControl{
+		Props:          e.Props,
+		Name:           field("name", "string"),
+		Value:          field("value", "string"),
+		DefaultValue:   field("defaultValue", "string"),
+		Checked:        field("checked", "bool"),
+		DefaultChecked: field("defaultChecked", "bool"),
+		Disabled:       field("disabled", "bool"),
+		Type:           field("type", "string"),
+	}
+	if c.Type == "" {
+		c.Checked, c.DefaultChecked = "", ""
+	}
+
+	return c, formControls[e.Name] && c.Name != "" && c.Value != ""
+}
+
+// generateFormValues renders with base FormValues and FormJSON for the form controls among the planned elements, if
+// any, into formValuesFile, and writes or diffs it according to opts.
+func generateFormValues(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	data := templFormValues{templTarget: target}
+	for _, k := range tags {
+		if c, ok := newTemplFormControl(planned[k]); ok {
+			data.Controls = append(data.Controls, c)
+		}
+	}
+	if len(data.Controls) == 0 {
+		return
+	}
+
+	generateShared(base, "formvalues", formValuesFile, data, opts, res, produced)
+}
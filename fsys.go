@@ -0,0 +1,187 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// FS is the writable file system Generate emits into. Names are the paths Generate computes by joining
+	// Options.Dir with the file names, so an implementation rooted elsewhere is free to interpret them relative to
+	// its own root. ReadFile and ReadDir report missing files with errors matching fs.ErrNotExist.
+	FS interface {
+		ReadFile(name string) ([]byte, error)
+		WriteFile(name string, data []byte) error
+		Remove(name string) error
+		ReadDir(name string) ([]fs.DirEntry, error)
+	}
+
+	// osFS is the FS of the operating system, used when Options.FS is nil.
+	osFS struct{}
+
+	// MemFS is an in-memory FS, for tools that embed the generator and want to capture its output without touching
+	// disk. Names are cleaned and use forward slashes; directories exist implicitly as prefixes of the files. The
+	// zero value is an empty file system ready to use, and a MemFS is safe for concurrent use.
+	MemFS struct {
+		mu    sync.Mutex
+		files map[string][]byte
+	}
+
+	// memEntry is a file or implicit directory of a MemFS listed by ReadDir.
+	memEntry struct {
+		name string
+		size int
+		dir  bool
+	}
+)
+
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+func (osFS) WriteFile(name string, data []byte) error { return writeFile(name, data) }
+
+func (osFS) Remove(name string) error { return os.Remove(name) }
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// memName normalizes name to the key under which a MemFS stores it.
+func memName(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// ReadFile returns a copy of the contents of the file name.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.files[memName(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile creates or replaces the file name with a copy of data.
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.files == nil {
+		m.files = map[string][]byte{}
+	}
+	m.files[memName(name)] = append([]byte(nil), data...)
+
+	return nil
+}
+
+// Remove deletes the file name.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := memName(name)
+	if _, ok := m.files[n]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, n)
+
+	return nil
+}
+
+// ReadDir lists the files and implicit directories directly inside the directory name, sorted by name. Every
+// directory exists, empty or not, so that Generate can emit into any Options.Dir.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prefix := memName(name) + "/"
+	if prefix == "./" {
+		prefix = ""
+	}
+
+	seen := map[string]*memEntry{}
+	for n, data := range m.files {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		rest := n[len(prefix):]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			seen[rest[:i]] = &memEntry{name: rest[:i], dir: true}
+		} else {
+			seen[rest] = &memEntry{name: rest, size: len(data)}
+		}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(seen))
+	for _, e := range seen {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+// Files returns the names of the files in m, sorted.
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.files))
+	for n := range m.files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (e *memEntry) Name() string { return e.name }
+
+func (e *memEntry) IsDir() bool { return e.dir }
+
+func (e *memEntry) Type() fs.FileMode { return e.Mode().Type() }
+
+func (e *memEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (e *memEntry) Size() int64 { return int64(e.size) }
+
+func (e *memEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (e *memEntry) ModTime() time.Time { return time.Time{} }
+
+func (e *memEntry) Sys() interface{} { return nil }
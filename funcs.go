@@ -0,0 +1,90 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// builtinFuncs are the functions available to every template, including those loaded with -templates, in addition
+// to the text/template builtins. Options.Funcs adds to or replaces them.
+var builtinFuncs = template.FuncMap{
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+	"lowerFirst":  lowerFirst,
+	"upperFirst":  upperFirst,
+	"snake":       snake,
+	"hasPrefix":   strings.HasPrefix,
+	"hasSuffix":   strings.HasSuffix,
+	"contains":    strings.Contains,
+	"join":        strings.Join,
+	"attrsOfType": attrsOfType,
+}
+
+// lowerFirst returns s with its first letter lower-cased, as in an unexported identifier.
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// upperFirst returns s with its first letter upper-cased, as in an exported identifier.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// snake converts the Go identifier s to snake case, keeping initialisms together: "BGColor" becomes "bg_color".
+func snake(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// attrsOfType returns the attributes among attrs whose Go type is one of types, for example
+// {{ range attrsOfType .AllAttrs "bool" }}.
+func attrsOfType(attrs []templAttr, types ...string) []templAttr {
+	var res []templAttr
+	for _, a := range attrs {
+		for _, t := range types {
+			if a.Type == t {
+				res = append(res, a)
+				break
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,1515 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build/constraint"
+	"go/format"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/template"
+	"time"
+
+	"golang.org/x/tools/imports"
+)
+
+type (
+	// Options configures a single call to Generate.
+	Options struct {
+		// Dir is the directory the generated Go files are written to.
+		Dir string
+
+		// Elements is the element table to generate, keyed by HTML tag name.
+		Elements map[string]Desc
+
+		// Groups holds the attribute groups the elements may list, keyed by group name.
+		Groups map[string][]Attr
+
+		// Package is the name of the generated package. It defaults to "react".
+		Package string
+
+		// ImportPath is the import path of the generated package, used by the generated tests. It defaults to
+		// "myitcv.io/react".
+		ImportPath string
+
+		// Naming extends the initialisms and words used to derive Go names from HTML names.
+		Naming Naming
+
+		// Header is the banner, such as a license or SPDX line, written below the generated marker of every file.
+		// Lines that are not already comments are turned into line comments, and any "Code generated" line is dropped
+		// because the marker is always written by Generate itself.
+		Header string
+
+		// Prune deletes previously generated files in Dir that Elements no longer produces.
+		Prune bool
+
+		// DryRun renders everything in memory and reports the differences against Dir in Result.Diffs instead of
+		// writing or deleting any file.
+		DryRun bool
+
+		// GoImports passes the generated sources through goimports, the golang.org/x/tools/imports package, so that
+		// import lists are added and pruned to match what the templates actually use.
+		GoImports bool
+
+		// Reserved lists identifiers declared by hand-written code in the target package, in addition to the
+		// built-in list of hand-written elements, that generated identifiers must not clash with.
+		Reserved []string
+
+		// HandWritten also generates the elements marked HandWritten, which are otherwise skipped because the target
+		// package maintains them by hand.
+		HandWritten bool
+
+		// OverrideDir is the directory of override files merged into the generated files. The override file of an
+		// element, such as a_override.go for <a>, is Go source whose declaration of the internal props struct, such
+		// as _AProps, adds its fields to the generated struct and whose other declarations are appended verbatim.
+		OverrideDir string
+
+		// TemplateDir is a directory of <name>.tmpl files replacing the embedded templates of the same name, and of
+		// element/<tag>.tmpl files replacing the template of the element <tag>.
+		TemplateDir string
+
+		// Funcs adds functions to the templates, replacing the built-in functions of the same name. Helpers are named
+		// templates, keyed by name, that the templates can invoke with {{ template "name" . }}.
+		Funcs   template.FuncMap
+		Helpers map[string]string
+
+		// Workers is the number of elements generated concurrently. Zero or less uses GOMAXPROCS.
+		Workers int
+
+		// KeepGoing continues with the remaining elements after an element fails validation, rendering, or
+		// formatting. Write failures never stop the run.
+		KeepGoing bool
+
+		// DetectBreaking compares the exported API of the files the run changes or prunes before and after, and
+		// reports what it removes or changes in Result.Breaking.
+		DetectBreaking bool
+
+		// CompileCheck builds the package in Dir with go build for GOOS=js once the files are written, so that output
+		// that does not compile is reported as failures of the elements it comes from. The go command must be in
+		// PATH and Dir must be inside a module or GOPATH able to resolve the package's imports. It is ignored in a
+		// dry run and when generation already failed.
+		CompileCheck bool
+
+		// Stubs constrains the element, options and registry files to js builds and generates, for each element,
+		// a <tag>_elem_stub.go file for the other platforms declaring its element and props types and a constructor
+		// returning a placeholder, so that packages using the elements can be vetted and tested natively. The stubs
+		// rely on the package declaring Element on every platform, and SyntheticEvent too if any element has an
+		// event handler attribute, since the event handler interfaces are then left unconstrained.
+		Stubs bool
+
+		// Build is the build constraint expression, such as "js && wasm", of the element, options and registry
+		// files, and TestBuild that of the tests. Empty values select the defaults: no constraint for the
+		// elements, or js with Stubs, and js for the tests. "none" omits the constraint. The stubs are
+		// constrained to the negation of Build.
+		Build, TestBuild string
+
+		// Tests is the layout of the generated tests: TestsEach, the default, TestsTable or TestsBoth.
+		Tests string
+
+		// Output, if set, receives every generated file instead of Dir, concatenated in path order in the txtar
+		// format: each file is preceded by a "-- name --" line giving its name relative to Dir. Nothing is read from
+		// or written to Dir, so protected regions are not carried over and Prune and CompileCheck have no effect.
+		// It cannot be combined with DryRun.
+		Output io.Writer
+
+		// FS is the file system the files are read from, written to and pruned from, the operating system's if nil.
+		// With another FS, such as a MemFS, CompileCheck has no effect since the go command only sees the disk.
+		FS FS
+
+		// Logger, if set, receives a debug record for every file written and an info record for every file pruned.
+		Logger *slog.Logger
+
+		// Progress, if set, is called as generation advances with the current phase, one of "render", "shared",
+		// "prune" and "compile", and the number of steps of the phase done out of total. Calls are serialized but
+		// may come from any goroutine.
+		Progress func(phase string, done, total int)
+
+		// TypedStyle replaces the style property of BasicHTMLElement, a *CSS, by an *InlineStyle in the props of
+		// every element, InlineStyle being generated with the common CSS properties typed as lengths, colors and
+		// numbers.
+		TypedStyle bool
+
+		// Refs adds to the props of every element a Ref field, a callback that React calls with the element's DOM
+		// node typed as in honnef.co/go/js/dom, such as *dom.HTMLVideoElement for <video>, once it is mounted.
+		Refs bool
+
+		// A11yTests also generates a test that runs axe-core against every element rendered with representative
+		// props, failing on the accessibility violations it finds.
+		A11yTests bool
+
+		// A11yLint makes the constructors of some elements warn in the browser console about common accessibility
+		// mistakes, such as an <img> without alt text, in the builds with the a11ylint tag. Without the tag the checks
+		// are compiled out.
+		A11yLint bool
+	}
+
+	// Result describes the outcome of a call to Generate.
+	Result struct {
+		// Written lists the paths of the files that were written because their contents changed, or that would have
+		// been in a dry run.
+		Written []string
+
+		// Pruned lists the paths of the stale generated files that were deleted, or that would have been in a dry run.
+		Pruned []string
+
+		// Diffs holds the unified diffs of the files a dry run would change, in path order.
+		Diffs []FileDiff
+
+		// Failures lists the elements and files that could not be generated, written, or deleted.
+		Failures []Failure
+
+		// Timings records how long each element took to render and format, in tag order.
+		Timings []ElementTiming
+
+		// Breaking lists the breaking changes to the exported API found with Options.DetectBreaking.
+		Breaking []BreakingChange
+
+		api      apiDelta
+		streamed []streamedFile
+	}
+
+	// streamedFile is a generated file held back to be written to Options.Output.
+	streamedFile struct {
+		name string
+		data []byte
+	}
+
+	// ElementTiming is the time spent rendering and formatting the files of a single element. Custom is set if an
+	// element/<tag> template replaced the primary template of the element.
+	ElementTiming struct {
+		Tag      string
+		Attrs    int
+		Custom   bool
+		Duration time.Duration
+	}
+
+	// FileDiff is the unified diff between a file in the output directory and its regenerated contents.
+	FileDiff struct {
+		Path string
+		Diff string
+	}
+
+	// elemOutcome collects what generating a single element produced, so that workers do not share a Result.
+	elemOutcome struct {
+		Result
+
+		// produced lists the names of the files the element generates, whether or not they were written.
+		produced []string
+
+		// failed reports that the element failed before reaching the file system.
+		failed bool
+	}
+
+	// Failure records an element or output file that could not be generated, the step that failed, and why.
+	Failure struct {
+		Element string
+		Path    string
+		Phase   Phase
+		Err     error
+	}
+
+	// Phase identifies the step of generation at which a Failure occurred.
+	Phase string
+)
+
+const (
+	// PhaseSpec is the validation of an element description.
+	PhaseSpec Phase = "spec"
+	// PhaseTemplate is the execution of a template.
+	PhaseTemplate Phase = "template"
+	// PhaseFormat is the gofmt pass over the rendered source.
+	PhaseFormat Phase = "format"
+	// PhaseMerge is the carrying over of the protected regions of an existing file.
+	PhaseMerge Phase = "merge"
+	// PhaseWrite is the writing, reading, or deletion of a file in the output directory.
+	PhaseWrite Phase = "write"
+	// PhaseCompile is the build of the written package requested by Options.CompileCheck.
+	PhaseCompile Phase = "compile"
+)
+
+// The layouts of the generated tests.
+const (
+	// TestsEach generates a <tag>_elem_test.go file per element.
+	TestsEach = "each"
+	// TestsTable generates a single table-driven test of every element into tableTestFile.
+	TestsTable = "table"
+	// TestsBoth generates both.
+	TestsBoth = "both"
+)
+
+// The package targeted when neither the spec nor the command line names one.
+const (
+	defaultPackage    = "react"
+	defaultImportPath = "myitcv.io/react"
+)
+
+// templates holds the parsed default templates. It is never executed directly: each call to Generate works on its own
+// clone so that concurrent calls cannot observe each other's template changes.
+var templates = func() *template.Template {
+	t := template.New("").Funcs(builtinFuncs)
+	for _, name := range templateNames {
+		b, err := templateFS.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			panic(err)
+		}
+		template.Must(t.New(name).Parse(string(b)))
+	}
+	return t
+}()
+
+// loadTemplates returns a copy of the default templates extended with funcs and with helpers, named templates that the
+// others can invoke, in which the templates found in dir, as <name>.tmpl files, replace the defaults of the same name.
+// Files element/<tag>.tmpl in dir are added as templates named element/<tag>, which replace the primary template for
+// the element <tag>. An empty dir leaves the defaults unchanged. The path of the file that could not be loaded, if
+// any, is returned with the error.
+func loadTemplates(dir string, funcs template.FuncMap, helpers map[string]string) (*template.Template, string, error) {
+	t, err := templates.Clone()
+	if err != nil {
+		return nil, "", err
+	}
+	t.Funcs(funcs)
+
+	var names []string
+	for name := range helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if t.Lookup(name) != nil {
+			return nil, "", fmt.Errorf("template helper %q replaces a template", name)
+		}
+		if _, err := t.New(name).Parse(helpers[name]); err != nil {
+			return nil, "", fmt.Errorf("template helper %q: %v", name, err)
+		}
+	}
+
+	if dir == "" {
+		return t, "", nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, dir, err
+	}
+
+	files := make(map[string]string)
+	for _, name := range templateNames {
+		files[name] = filepath.Join(dir, name+".tmpl")
+	}
+	elems, _ := filepath.Glob(filepath.Join(dir, "element", "*.tmpl"))
+	for _, p := range elems {
+		files["element/"+strings.TrimSuffix(filepath.Base(p), ".tmpl")] = p
+	}
+
+	for name, p := range files {
+		b, err := os.ReadFile(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err == nil {
+			_, err = t.New(name).Parse(string(b))
+		}
+		if err != nil {
+			return nil, p, err
+		}
+	}
+
+	return t, "", nil
+}
+
+// Generate renders and writes the Go wrappers for every element in opts.Elements. Failures are reported in the Result
+// rather than aborting the run; the first element that fails before reaching the file system stops generation unless
+// opts.KeepGoing is set.
+//
+// Elements are generated by a pool of opts.Workers goroutines, each executing its own clone of the templates. Generate
+// is itself safe to call concurrently from multiple goroutines, for example to generate different profiles into
+// different directories, as long as the calls do not share an output directory or mutate opts.Elements while running.
+func Generate(opts Options) (*Result, error) {
+	if opts.Output != nil && opts.DryRun {
+		return nil, errors.New("output to a writer cannot be combined with a dry run")
+	}
+	if opts.FS == nil {
+		opts.FS = osFS{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+	if opts.Progress == nil {
+		opts.Progress = func(string, int, int) {}
+	}
+	if _, onDisk := opts.FS.(osFS); opts.CompileCheck && !opts.DryRun && onDisk {
+		if _, err := exec.LookPath("go"); err != nil {
+			return nil, fmt.Errorf("compile check requested but the go command is not available: %v", err)
+		}
+	}
+
+	target, err := newTemplTarget(opts.Package, opts.ImportPath)
+	if err != nil {
+		return nil, err
+	}
+	target.Header = commentHeader(opts.Header)
+	target.Stubs = opts.Stubs
+	target.Refs = opts.Refs
+	target.Lint = opts.A11yLint
+	switch opts.Tests {
+	case "", TestsEach, TestsTable, TestsBoth:
+	default:
+		return nil, fmt.Errorf("invalid test layout %q: want %s, %s or %s", opts.Tests, TestsEach, TestsTable, TestsBoth)
+	}
+	if err := setConstraints(&target, opts); err != nil {
+		return nil, err
+	}
+	target.Version = version
+	for _, a := range basicAttrs {
+		if opts.TypedStyle && a.Name == styleAttr.JS {
+			continue
+		}
+		target.Basic = append(target.Basic, newTemplAttr(a, defaultNamer))
+	}
+	if target.SpecSum, err = specSum(target, opts); err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for k, d := range opts.Elements {
+		if d.HandWritten && !opts.HandWritten {
+			continue
+		}
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+
+	// Plan every element's identifiers before anything is written, so that a clash does not leave a half-updated
+	// package behind.
+	namer := NewNamer(opts.Naming)
+	groups, failures := newTemplGroups(opts.Groups, namer)
+	if len(failures) > 0 {
+		return &Result{Failures: failures}, nil
+	}
+
+	planned := make(map[string]templElem)
+	var valid []string
+	for _, k := range tags {
+		err := validateElem(k, opts.Elements[k], opts.Groups)
+		if err == nil {
+			err = validateElemName(k, opts.Elements[k], namer)
+		}
+		if err != nil {
+			failures = append(failures, Failure{Element: k, Phase: PhaseSpec, Err: err})
+			continue
+		}
+		planned[k] = newTemplElem(k, opts.Elements[k], namer, groups)
+		valid = append(valid, k)
+	}
+	if collisions := findCollisions(valid, planned, reservedFor(opts, planned), opts.Refs, opts.TypedStyle); len(collisions) > 0 {
+		return &Result{Failures: append(failures, collisions...)}, nil
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(tags) {
+		workers = len(tags)
+	}
+
+	base, p, err := loadTemplates(opts.TemplateDir, opts.Funcs, opts.Helpers)
+	if err != nil {
+		return &Result{Failures: []Failure{{Path: p, Phase: PhaseTemplate, Err: err}}}, nil
+	}
+
+	var (
+		outcomes = make([]*elemOutcome, len(tags))
+		jobs     = make(chan int)
+		stop     atomic.Bool
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+	)
+	opts.Progress("render", 0, len(tags))
+	for w := 0; w < workers; w++ {
+		t, err := base.Clone()
+		if err != nil {
+			close(jobs)
+			wg.Wait()
+			return nil, err
+		}
+
+		wg.Add(1)
+		go func(t *template.Template) {
+			defer wg.Done()
+			for i := range jobs {
+				o := generateElem(tags[i], opts.Elements[tags[i]], t, target, namer, groups, opts)
+				outcomes[i] = o
+				if o.failed && !opts.KeepGoing {
+					stop.Store(true)
+				}
+
+				mu.Lock()
+				done++
+				opts.Progress("render", done, len(tags))
+				mu.Unlock()
+			}
+		}(t)
+	}
+	for i := range tags {
+		if stop.Load() {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Merge the outcomes in tag order so that the Result does not depend on scheduling.
+	res := new(Result)
+	produced := make(map[string]bool)
+	if !stop.Load() {
+		opts.Progress("shared", 0, 1)
+		generateGroups(base, tags, planned, target, opts, res, produced)
+		if len(tags) > 0 {
+			generateOptions(base, target, opts, res, produced)
+			generateClasses(base, target, opts, res, produced)
+			generateDebug(base, target, opts, res, produced)
+			generateRoles(base, target, opts, res, produced)
+			if opts.A11yLint {
+				generateA11yLint(base, target, opts, res, produced)
+			}
+			generateEnums(base, tags, planned, target, opts, res, produced)
+			generateValueTypes(base, tags, planned, target, opts, res, produced)
+			generateHelpers(base, tags, planned, target, opts, res, produced)
+			generateSocialMeta(base, tags, planned, target, opts, res, produced)
+			generateHeadBuilder(base, tags, planned, target, opts, res, produced)
+			generateRegistry(base, tags, planned, target, opts, res, produced)
+			generateForElems(base, "shallow", shallowFile, tags, planned, target, opts, res, produced)
+			generateForElems(base, "meta", metaFile, tags, planned, target, opts, res, produced)
+			generateEvents(base, tags, planned, target, opts, res, produced)
+			generateFormValues(base, tags, planned, target, opts, res, produced)
+			if opts.TypedStyle {
+				generateStyle(base, target, opts, res, produced)
+			}
+			if opts.Tests == TestsTable || opts.Tests == TestsBoth {
+				generateForElems(base, "tabletest", tableTestFile, tags, planned, target, opts, res, produced)
+			}
+			generatePropsTest(base, tags, planned, target, opts, res, produced)
+			if opts.A11yTests {
+				generateForElems(base, "a11ytest", a11yTestFile, tags, planned, target, opts, res, produced)
+			}
+		}
+		opts.Progress("shared", 1, 1)
+	}
+	for _, o := range outcomes {
+		if o == nil {
+			continue
+		}
+		for _, n := range o.produced {
+			produced[n] = true
+		}
+		res.Written = append(res.Written, o.Written...)
+		res.Diffs = append(res.Diffs, o.Diffs...)
+		res.Failures = append(res.Failures, o.Failures...)
+		res.Timings = append(res.Timings, o.Timings...)
+		res.api.merge(o.api)
+		res.streamed = append(res.streamed, o.streamed...)
+	}
+
+	if opts.Output != nil {
+		sort.Slice(res.streamed, func(i, j int) bool { return res.streamed[i].name < res.streamed[j].name })
+		for _, f := range res.streamed {
+			if _, err := fmt.Fprintf(opts.Output, "-- %s --\n%s", f.name, f.data); err != nil {
+				res.Failures = append(res.Failures, Failure{Path: f.name, Phase: PhaseWrite, Err: err})
+				break
+			}
+		}
+		return res, nil
+	}
+	// A run that stopped early has not produced every file, so pruning would delete outputs that are still current.
+	if opts.Prune && !stop.Load() {
+		opts.Progress("prune", 0, 1)
+		pruneStale(opts, produced, res)
+		opts.Progress("prune", 1, 1)
+	}
+	if opts.DetectBreaking {
+		res.Breaking = res.api.breaking()
+	}
+	if _, onDisk := opts.FS.(osFS); opts.CompileCheck && onDisk && !opts.DryRun && !stop.Load() && len(res.Failures) == 0 {
+		opts.Progress("compile", 0, 1)
+		buildPackage(opts.Dir, planned, res)
+		opts.Progress("compile", 1, 1)
+	}
+
+	return res, nil
+}
+
+// reservedFor returns the identifiers that the planned elements must not declare: opts.Reserved and the hand-written
+// identifiers, except those of hand-written elements that are being generated to replace them.
+func reservedFor(opts Options, planned map[string]templElem) []string {
+	replaced := make(map[string]bool)
+	for k, e := range planned {
+		if opts.Elements[k].HandWritten {
+			replaced[e.Upper], replaced[e.Elem], replaced[e.Props] = true, true, true
+		}
+	}
+
+	// An identifier listed more than once would collide with itself.
+	var reserved []string
+	seen := make(map[string]bool)
+	for _, id := range opts.Reserved {
+		if !seen[id] {
+			seen[id] = true
+			reserved = append(reserved, id)
+		}
+	}
+	for _, id := range handWritten {
+		if !replaced[id] && !seen[id] {
+			seen[id] = true
+			reserved = append(reserved, id)
+		}
+	}
+
+	return reserved
+}
+
+// groupsFile is the name of the file declaring the attribute group structs.
+const groupsFile = "attrgroups.go"
+
+// generateGroups renders with base the structs of the attribute groups used by the planned elements into groupsFile,
+// and writes or diffs it according to opts. Nothing is produced if no element uses a group. The doc comment of a group
+// lists the elements sharing it, unless they are all of them, as for the global groups.
+func generateGroups(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	users := make(map[*templGroup][]string)
+	for _, k := range tags {
+		for _, g := range planned[k].Groups {
+			users[g] = append(users[g], "<"+k+">")
+		}
+	}
+	if len(users) == 0 {
+		return
+	}
+
+	data := templGroups{templTarget: target}
+	for g, u := range users {
+		g.Users = strings.Join(u, ", ")
+		if len(u) == len(tags) {
+			g.Users = "every element"
+		}
+		data.Groups = append(data.Groups, g)
+	}
+	sort.Slice(data.Groups, func(i, j int) bool { return data.Groups[i].Type < data.Groups[j].Type })
+
+	generateShared(base, "groups", groupsFile, data, opts, res, produced)
+}
+
+// optionsFile is the name of the file declaring the options shared by the ...Opt constructors of all elements.
+const optionsFile = "options.go"
+
+// generateOptions renders with base the options that set the properties of BasicHTMLElement into optionsFile, and
+// writes or diffs it according to opts.
+func generateOptions(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "options", optionsFile, target, opts, res, produced)
+}
+
+// classesFile is the name of the file declaring Classes and ClassMap.
+const classesFile = "classes.go"
+
+// generateClasses renders with base the className composition helpers into classesFile, and writes or diffs it
+// according to opts.
+func generateClasses(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "classes", classesFile, target, opts, res, produced)
+}
+
+// registryFile is the name of the file declaring ElementsByTag.
+const registryFile = "registry.go"
+
+// generateRegistry renders with base the registry of the planned elements, keyed by tag name, into registryFile, and
+// writes or diffs it according to opts.
+func generateRegistry(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	data := templRegistry{templTarget: target}
+	for _, k := range tags {
+		e := planned[k]
+		e.templTarget = target
+		data.Elems = append(data.Elems, e)
+	}
+
+	generateShared(base, "registry", registryFile, data, opts, res, produced)
+}
+
+// shallowFile is the name of the file declaring the shallow rendering of the generated elements.
+const shallowFile = "shallow.go"
+
+// metaFile is the name of the file describing the generated elements in ElementsMeta.
+const metaFile = "elements_meta_gen.go"
+
+// eventsFile is the name of the file declaring the event handler interfaces and sameHandler.
+const eventsFile = "events.go"
+
+// generateEvents renders with base the declarations of the eventHandlers that the attributes of the planned elements
+// use, and the sameHandler that their Equal methods call, into eventsFile, and writes or diffs it according to opts.
+func generateEvents(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	used := make(map[string]bool)
+	for _, k := range tags {
+		for _, a := range planned[k].ElemAttrs() {
+			if _, ok := eventHandlers[a.Type]; ok {
+				used[a.Type] = true
+			}
+		}
+	}
+
+	data := templEvents{templTarget: target}
+	for t := range used {
+		data.Handlers = append(data.Handlers, templHandler{Type: t, Event: eventHandlers[t]})
+	}
+	sort.Slice(data.Handlers, func(i, j int) bool { return data.Handlers[i].Type < data.Handlers[j].Type })
+
+	generateShared(base, "events", eventsFile, data, opts, res, produced)
+}
+
+// tableTestFile is the name of the file holding the table-driven test of every element.
+const tableTestFile = "elements_test.go"
+
+// a11yTestFile is the name of the file holding the accessibility test of every element.
+const a11yTestFile = "a11y_test.go"
+
+// propsTestFile is the name of the file holding the round-trip tests of the props of every element.
+const propsTestFile = "props_test.go"
+
+// generatePropsTest renders with base the round-trip tests of the props of the planned elements into propsTestFile,
+// and writes or diffs it according to opts. Nothing is produced if no element has an attribute to test.
+func generatePropsTest(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	data := templRegistry{templTarget: target}
+	tested := false
+	for _, k := range tags {
+		e := planned[k]
+		tested = tested || len(e.RoundTrip()) > 0
+		data.Elems = append(data.Elems, e)
+	}
+	if !tested {
+		return
+	}
+
+	generateShared(base, "proptest", propsTestFile, data, opts, res, produced)
+}
+
+// generateForElems renders the template name of base for the planned elements, such as the shallow rendering or the
+// table-driven test of every element, into the package file f, and writes or diffs it according to opts.
+func generateForElems(base *template.Template, name, f string, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	data := templRegistry{templTarget: target}
+	for _, k := range tags {
+		data.Elems = append(data.Elems, planned[k])
+	}
+
+	generateShared(base, name, f, data, opts, res, produced)
+}
+
+// generateShared renders the template name of base for data into the package file f, which is shared by all elements
+// rather than generated for one of them, and writes or diffs it according to opts.
+func generateShared(base *template.Template, name, f string, data interface{}, opts Options, res *Result, produced map[string]bool) {
+	p := filepath.Join(opts.Dir, f)
+	produced[f] = true
+
+	src, phase, err := executeTemplate(base.Lookup(name), data, opts.GoImports)
+	if err != nil {
+		res.Failures = append(res.Failures, Failure{Path: p, Phase: phase, Err: err})
+		return
+	}
+	if err := outputFile(p, src, opts, res); err != nil {
+		res.Failures = append(res.Failures, Failure{Path: p, Phase: PhaseWrite, Err: err})
+	}
+}
+
+// generateElem renders the files of the element k described by d into the package target using the templates in t,
+// the names chosen by namer, and the resolved attribute groups, and writes or diffs them according to opts.
+func generateElem(k string, d Desc, t *template.Template, target templTarget, namer *Namer, groups map[string]*templGroup, opts Options) *elemOutcome {
+	o := new(elemOutcome)
+	err := validateElem(k, d, opts.Groups)
+	if err == nil {
+		err = validateElemName(k, d, namer)
+	}
+	if err != nil {
+		o.Failures = append(o.Failures, Failure{Element: k, Phase: PhaseSpec, Err: err})
+		o.failed = true
+		return o
+	}
+
+	e := newTemplElem(k, d, namer, groups)
+	e.templTarget = target
+	if opts.TypedStyle {
+		e.Attrs = append(e.Attrs, styleAttr)
+	}
+	if opts.OverrideDir != "" {
+		if e.Override, err = loadOverride(opts.OverrideDir, k, e); err != nil {
+			o.Failures = append(o.Failures, Failure{Element: k, Phase: PhaseSpec, Err: err})
+			o.failed = true
+			return o
+		}
+	}
+	primary := primaryFor(t, k)
+	timing := ElementTiming{Tag: k, Attrs: len(e.Attrs), Custom: primary != "primary"}
+	for _, g := range e.Groups {
+		timing.Attrs += len(g.Attrs)
+	}
+
+	files := []struct {
+		name, templ string
+	}{
+		{fileBase(k) + "_elem.go", primary},
+	}
+	if opts.Tests != TestsTable {
+		files = append(files, struct{ name, templ string }{fileBase(k) + "_elem_test.go", "test"})
+	}
+	if opts.Stubs {
+		files = append(files, struct{ name, templ string }{fileBase(k) + "_elem_stub.go", "stub"})
+	}
+	for _, f := range files {
+		p := filepath.Join(opts.Dir, f.name)
+		o.produced = append(o.produced, f.name)
+		start := time.Now()
+		data, phase, err := executeTemplate(t.Lookup(f.templ), e, opts.GoImports)
+		timing.Duration += time.Since(start)
+		if err == nil {
+			if opts.Output == nil {
+				data, phase, err = preserveRegions(opts.FS, p, data)
+			}
+		}
+		if err != nil {
+			o.Failures = append(o.Failures, Failure{Element: k, Path: p, Phase: phase, Err: err})
+			o.failed = true
+			continue
+		}
+
+		if err := outputFile(p, data, opts, &o.Result); err != nil {
+			o.Failures = append(o.Failures, Failure{Element: k, Path: p, Phase: PhaseWrite, Err: err})
+		}
+	}
+
+	o.Timings = append(o.Timings, timing)
+	return o
+}
+
+// primaryFor returns the name of the template in t that renders the primary file of the element k: its
+// element/<tag> template if there is one, and otherwise the primary template.
+func primaryFor(t *template.Template, k string) string {
+	if t.Lookup("element/"+k) != nil {
+		return "element/" + k
+	}
+	return "primary"
+}
+
+// preserveRegions carries the protected regions of the existing file p of fsys over into data, the regenerated contents
+// of p, and reformats the result.
+func preserveRegions(fsys FS, p string, data []byte) ([]byte, Phase, error) {
+	old, err := fsys.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, "", nil
+	} else if err != nil {
+		return nil, PhaseWrite, err
+	}
+
+	merged, err := mergeRegions(old, data)
+	if err != nil {
+		return nil, PhaseMerge, err
+	}
+	if bytes.Equal(merged, data) {
+		return data, "", nil
+	}
+
+	formatted, err := format.Source(merged)
+	if err != nil {
+		return nil, PhaseFormat, err
+	}
+
+	return formatted, "", nil
+}
+
+// fileBase returns the prefix of the names of the files generated for the element k. The hyphens of custom element
+// names are replaced by underscores.
+func fileBase(k string) string {
+	return strings.ReplaceAll(k, "-", "_")
+}
+
+// outputFile writes data to the file p and records it in res unless p already holds data, or in a dry run records the
+// difference between data and the current contents of p.
+func outputFile(p string, data []byte, opts Options, res *Result) error {
+	if opts.Output != nil {
+		name, err := filepath.Rel(opts.Dir, p)
+		if err != nil {
+			name = p
+		}
+		res.streamed = append(res.streamed, streamedFile{name: filepath.ToSlash(name), data: data})
+		res.Written = append(res.Written, p)
+		return nil
+	}
+
+	old, err := opts.FS.ReadFile(p)
+	if opts.DetectBreaking && err == nil && !bytes.Equal(old, data) {
+		res.api.record(p, old, data)
+	}
+
+	if opts.DryRun {
+		return diffFile(opts.FS, p, data, res)
+	}
+
+	if err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	if err := opts.FS.WriteFile(p, data); err != nil {
+		return err
+	}
+	opts.Logger.Debug("wrote", "file", p, "bytes", len(data))
+	res.Written = append(res.Written, p)
+
+	return nil
+}
+
+// diffFile compares data with the current contents of the file p of fsys and records the difference, if any, in res.
+func diffFile(fsys FS, p string, data []byte, res *Result) error {
+	old, err := fsys.ReadFile(p)
+	oldName := p
+	if errors.Is(err, fs.ErrNotExist) {
+		oldName = os.DevNull
+	} else if err != nil {
+		return err
+	}
+
+	if d := unifiedDiff(oldName, p, old, data); d != "" {
+		res.Written = append(res.Written, p)
+		res.Diffs = append(res.Diffs, FileDiff{Path: p, Diff: d})
+	}
+
+	return nil
+}
+
+// newTemplTarget validates the package name and import path, substituting the defaults for empty values.
+func newTemplTarget(pkg, importPath string) (templTarget, error) {
+	if pkg == "" {
+		pkg = defaultPackage
+	}
+	if importPath == "" {
+		importPath = defaultImportPath
+	}
+
+	if !token.IsIdentifier(pkg) || pkg == "_" {
+		return templTarget{}, fmt.Errorf("invalid package name %q", pkg)
+	}
+	if !validImportPath(importPath) {
+		return templTarget{}, fmt.Errorf("invalid import path %q", importPath)
+	}
+
+	t := templTarget{Package: pkg, ImportPath: importPath}
+	if path.Base(importPath) != pkg {
+		t.ImportAlias = pkg
+	}
+
+	return t, nil
+}
+
+// buildExpr returns the build constraint expression of the element files: opts.Build, which defaults to js with stubs.
+func buildExpr(opts Options) string {
+	if opts.Build == "" && opts.Stubs {
+		return "js"
+	}
+
+	return opts.Build
+}
+
+// setConstraints sets the build constraint lines of target from opts.Build and opts.TestBuild.
+func setConstraints(target *templTarget, opts Options) error {
+	build := buildExpr(opts)
+	test := opts.TestBuild
+	if test == "" {
+		test = "js"
+	}
+
+	var err error
+	if target.Constraint, err = constraintLines(build); err != nil {
+		return fmt.Errorf("build constraint: %v", err)
+	}
+	if target.TestConstraint, err = constraintLines(test); err != nil {
+		return fmt.Errorf("test build constraint: %v", err)
+	}
+	if opts.Stubs {
+		if target.Constraint == "" {
+			return errors.New("stubs need a build constraint for the elements")
+		}
+		target.StubConstraint, _ = constraintLines("!(" + build + ")")
+	}
+
+	return nil
+}
+
+// constraintLines returns the //go:build line for the build constraint expression expr, followed by the equivalent
+// // +build lines understood by Go releases before 1.17, or nothing if expr is empty or "none".
+func constraintLines(expr string) (string, error) {
+	if expr == "" || expr == "none" {
+		return "", nil
+	}
+	x, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return "", fmt.Errorf("%q: %v", expr, err)
+	}
+	lines := []string{"//go:build " + x.String()}
+	plus, err := constraint.PlusBuildLines(x)
+	if err != nil {
+		return "", fmt.Errorf("%q: %v", expr, err)
+	}
+
+	return strings.Join(append(lines, plus...), "\n"), nil
+}
+
+// specSum returns the hex SHA-256 digest of the canonical JSON encoding of the spec being generated.
+func specSum(target templTarget, opts Options) (string, error) {
+	b, err := json.Marshal(Spec{
+		Package:         target.Package,
+		ImportPath:      target.ImportPath,
+		Naming:          opts.Naming,
+		Elements:        opts.Elements,
+		Groups:          opts.Groups,
+		TemplateHelpers: opts.Helpers,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// generatedLine matches the "Code generated" marker line recognized by Go tooling.
+var generatedLine = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// commentHeader turns h into a block of line comments, dropping blank lines at either end and any "Code generated"
+// marker line.
+func commentHeader(h string) string {
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSpace(h), "\n") {
+		l = strings.TrimRight(l, " \t\r")
+		switch {
+		case generatedLine.MatchString(l):
+			continue
+		case l == "":
+			l = "//"
+		case !strings.HasPrefix(l, "//"):
+			l = "// " + l
+		}
+		lines = append(lines, l)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// validImportPath reports whether p is a plausible import path: slash-separated non-empty elements that contain no
+// spaces, quotes, or backslashes and are not relative.
+func validImportPath(p string) bool {
+	if strings.ContainsAny(p, " \t\"`\\") {
+		return false
+	}
+
+	for _, elem := range strings.Split(p, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateElem checks that the element k described by d can be rendered with the attribute groups in groups.
+func validateElem(k string, d Desc, groups map[string][]Attr) error {
+	if k == "" {
+		return errors.New("empty tag name")
+	}
+
+	seen := make(map[string]bool)
+	for _, n := range d.Groups {
+		g, ok := groups[n]
+		if !ok {
+			return fmt.Errorf("unknown attribute group %q", n)
+		}
+		for _, a := range g {
+			if seen[a.Name] {
+				return fmt.Errorf("attribute %q of group %q is already defined", a.Name, n)
+			}
+			seen[a.Name] = true
+		}
+	}
+
+	for _, f := range d.Formerly {
+		if !token.IsIdentifier(f) || !token.IsExported(f) {
+			return fmt.Errorf("former name %q is not an exported identifier", f)
+		}
+	}
+
+	for i, a := range d.Attributes {
+		if a.Name == "" {
+			return fmt.Errorf("attribute %d has an empty name", i)
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("duplicate attribute %q", a.Name)
+		}
+		seen[a.Name] = true
+	}
+
+	return nil
+}
+
+// newTemplGroups resolves the Go names and types of the attribute groups.
+func newTemplGroups(groups map[string][]Attr, n *Namer) (map[string]*templGroup, []Failure) {
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := make(map[string]*templGroup)
+	var failures []Failure
+	for _, name := range names {
+		if err := validateElem(name, Desc{Attributes: groups[name]}, nil); err != nil {
+			failures = append(failures, Failure{Phase: PhaseSpec, Err: fmt.Errorf("attribute group %q: %v", name, err)})
+			continue
+		}
+
+		g := &templGroup{Name: name, Type: n.Name(name) + "Attrs"}
+		for _, a := range groups[name] {
+			g.Attrs = append(g.Attrs, newTemplAttr(a, n))
+		}
+		resolved[name] = g
+	}
+
+	return resolved, failures
+}
+
+// validateElemName checks that the Go name of the element k described by d, its override or the name n gives k, is an
+// exported identifier, from which those of its types and constructors are derived.
+func validateElemName(k string, d Desc, n *Namer) error {
+	upper := d.Override
+	if upper == "" {
+		upper = n.Name(k)
+	}
+	if !token.IsIdentifier(upper) || !token.IsExported(upper) {
+		return fmt.Errorf("Go name %q is not an exported identifier; set an Override", upper)
+	}
+
+	return nil
+}
+
+// newTemplElem resolves the Go names and types of the element k described by d, naming them with n unless they are
+// overridden, and looks up its attribute groups in groups.
+func newTemplElem(k string, d Desc, n *Namer, groups map[string]*templGroup) templElem {
+	var upper string
+	if d.Override != "" {
+		upper = d.Override
+	} else {
+		upper = n.Name(k)
+	}
+
+	var attrs []templAttr
+	for _, a := range d.Attributes {
+		attrs = append(attrs, newTemplAttr(a, n))
+	}
+
+	var gs []*templGroup
+	for _, g := range d.Groups {
+		gs = append(gs, groups[g])
+	}
+
+	return templElem{
+		Elem:       upper + "Elem",
+		Name:       k,
+		Props:      upper + "Props",
+		Upper:      upper,
+		Option:     upper + "Option",
+		OptionFunc: strings.ToLower(upper[:1]) + upper[1:] + "Option",
+		Groups:     gs,
+		Attrs:      attrs,
+		Formerly:   d.Formerly,
+
+		Experimental: d.Experimental,
+		DOMType:      domType(k),
+		Void:         voidElements[k],
+		A11yTested:   !a11yUntested[k],
+		Category:     elementCategory(k),
+		RefMethods:   refMethods[k],
+	}
+}
+
+// domImport is the import spec of the package declaring the DOM node types of the elements.
+const domImport = `dom "honnef.co/go/js/dom"`
+
+// Imports returns the import specs of the element file of e: the DOM package if it declares a Ref field, and those of
+// its override file, without duplicates.
+func (e templElem) Imports() []string {
+	var specs []string
+	if e.Refs {
+		specs = append(specs, domImport)
+	}
+	if e.Override != nil {
+		for _, is := range e.Override.Imports {
+			if is == domImport || (e.Refs && is == `"honnef.co/go/js/dom"`) {
+				continue
+			}
+			specs = append(specs, is)
+		}
+	}
+
+	return specs
+}
+
+// AllAttrs returns the attributes of every props field e generates code for: those of BasicHTMLElement followed by
+// ElemAttrs.
+func (e templElem) AllAttrs() []templAttr {
+	return append(append([]templAttr(nil), e.Basic...), e.ElemAttrs()...)
+}
+
+// control is an attribute of a form control holding its current value, paired with the React prop holding its initial
+// value.
+type control struct {
+	Value, Default templAttr
+}
+
+// Controls returns the attributes of e that make it controlled and that e also has the uncontrolled counterpart of;
+// see controlledProps.
+func (e templElem) Controls() []control {
+	attrs := make(map[string]templAttr)
+	for _, a := range e.ElemAttrs() {
+		attrs[a.JS] = a
+	}
+
+	var controls []control
+	for _, a := range e.ElemAttrs() {
+		if d, ok := attrs[controlledProps[a.JS]]; ok && d.Type == a.Type {
+			controls = append(controls, control{Value: a, Default: d})
+		}
+	}
+
+	return controls
+}
+
+// StubAttrs returns the attributes of the props fields of e's stub: the string properties of BasicHTMLElement, among
+// them the key React uses to tell the items of a list apart, followed by ElemAttrs.
+func (e templElem) StubAttrs() []templAttr {
+	var attrs []templAttr
+	for _, a := range e.Basic {
+		if a.Type == "string" {
+			attrs = append(attrs, a)
+		}
+	}
+
+	return append(attrs, e.ElemAttrs()...)
+}
+
+// ElemAttrs returns the attributes specific to the element e: those of its attribute groups followed by its own.
+func (e templElem) ElemAttrs() []templAttr {
+	var attrs []templAttr
+	for _, g := range e.Groups {
+		attrs = append(attrs, g.Attrs...)
+	}
+
+	return append(attrs, e.Attrs...)
+}
+
+// newTemplAttr resolves the Go name and type of the attribute a, naming it with n unless it is overridden.
+func newTemplAttr(a Attr, n *Namer) templAttr {
+	js := a.Name
+	var name string
+	if a.Override == "" {
+		name = n.Name(js)
+	} else {
+		name = a.Override
+	}
+	var t string
+	if a.Type == "" {
+		t = "string"
+	} else {
+		t = a.Type
+	}
+
+	return templAttr{Name: name, JS: js, Type: t, Compat: a.Compat, Experimental: a.Experimental}
+}
+
+// Handler reports whether the attribute holds an event handler, an interface value whose dynamic type may not be
+// comparable: one of the eventHandlers, or the OnChange or OnClick of BasicHTMLElement.
+func (a templAttr) Handler() bool {
+	_, ok := eventHandlers[a.Type]
+	return ok || a.Type == "OnChange" || a.Type == "OnClick"
+}
+
+// Pointer reports whether the attribute's Go type is a pointer type.
+func (a templAttr) Pointer() bool {
+	return strings.HasPrefix(a.Type, "*")
+}
+
+// JSBacked reports whether the attribute's Go type is one of the jsBackedTypes.
+func (a templAttr) JSBacked() bool {
+	return jsBackedTypes[a.Type]
+}
+
+// RoundTrip returns the attributes of the element, including those of its groups, whose values can be read back from
+// JavaScript and compared: those of type string, bool, int or float64.
+func (e templElem) RoundTrip() []templAttr {
+	var attrs []templAttr
+	for _, a := range e.ElemAttrs() {
+		switch a.Type {
+		case "string", "bool", "int", "float64":
+			attrs = append(attrs, a)
+		}
+	}
+
+	return attrs
+}
+
+// A11yAttrs returns the string attributes of the element, including those of its groups, that give it an accessible
+// name or text alternative.
+func (e templElem) A11yAttrs() []templAttr {
+	var attrs []templAttr
+	for _, a := range e.ElemAttrs() {
+		if a11yNames[a.JS] && a.Type == "string" {
+			attrs = append(attrs, a)
+		}
+	}
+
+	return attrs
+}
+
+// Sample returns a Go literal of the attribute's type, which must be one of those of RoundTrip, that differs from the
+// zero value.
+func (a templAttr) Sample() string {
+	switch a.Type {
+	case "bool":
+		return "true"
+	case "int":
+		return "42"
+	case "float64":
+		return "1.5"
+	}
+
+	return strconv.Quote(a.JS + "-value")
+}
+
+// Zero returns a Go expression of the attribute's zero value.
+func (a templAttr) Zero() string {
+	switch {
+	case a.Pointer():
+		return "nil"
+	case a.Type == "bool":
+		return "false"
+	case a.Type == "string":
+		return `""`
+	case knownTypes[a.Type]:
+		return "0"
+	}
+
+	return "*new(" + a.Type + ")"
+}
+
+// Kind returns the attribute's Go type with its first letter upper-cased, naming the coerce function that converts
+// loosely-typed values to it.
+func (a templAttr) Kind() string {
+	return strings.ToUpper(a.Type[:1]) + a.Type[1:]
+}
+
+// executeTemplate renders t for data and returns the formatted Go source, or the phase that failed and why. With
+// goImports set the source is formatted by goimports rather than gofmt.
+func executeTemplate(t *template.Template, data interface{}, goImports bool) ([]byte, Phase, error) {
+	b := new(bytes.Buffer)
+	if err := t.Execute(b, data); err != nil {
+		return nil, PhaseTemplate, err
+	}
+
+	var formatted []byte
+	var err error
+	if goImports {
+		formatted, err = imports.Process("", b.Bytes(), nil)
+	} else {
+		formatted, err = format.Source(b.Bytes())
+	}
+	if err != nil {
+		return nil, PhaseFormat, err
+	}
+
+	return formatted, "", nil
+}
+
+// writeFile atomically replaces the file p with data: the data is written to a temporary file in the same directory
+// which is then renamed over p, so that readers never see a truncated file and a full file system leaves the previous
+// contents intact.
+func writeFile(p string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, p)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+
+	return err
+}
+
+// pruneStale removes the generated files in opts.Dir whose names are not in produced. Only files carrying the
+// generated marker are considered, so hand-written sources are never touched. In a dry run the deletions are only
+// reported as diffs.
+func pruneStale(opts Options, produced map[string]bool, res *Result) {
+	dir := opts.Dir
+	entries, err := opts.FS.ReadDir(dir)
+	if err != nil {
+		res.Failures = append(res.Failures, Failure{Path: dir, Phase: PhaseWrite, Err: err})
+		return
+	}
+
+	for _, ent := range entries {
+		n := ent.Name()
+		if ent.IsDir() || filepath.Ext(n) != ".go" || produced[n] {
+			continue
+		}
+
+		p := filepath.Join(dir, n)
+		generated, err := isGenerated(opts.FS, p)
+		if err != nil {
+			res.Failures = append(res.Failures, Failure{Path: p, Phase: PhaseWrite, Err: err})
+			continue
+		}
+		if !generated {
+			continue
+		}
+
+		if opts.DetectBreaking {
+			if old, err := opts.FS.ReadFile(p); err == nil {
+				res.api.record(p, old, nil)
+			}
+		}
+		if opts.DryRun {
+			old, err := opts.FS.ReadFile(p)
+			if err != nil {
+				res.Failures = append(res.Failures, Failure{Path: p, Phase: PhaseWrite, Err: err})
+				continue
+			}
+			res.Pruned = append(res.Pruned, p)
+			res.Diffs = append(res.Diffs, FileDiff{Path: p, Diff: unifiedDiff(p, os.DevNull, old, nil)})
+			continue
+		}
+
+		if err := opts.FS.Remove(p); err != nil {
+			res.Failures = append(res.Failures, Failure{Path: p, Phase: PhaseWrite, Err: err})
+			continue
+		}
+		opts.Logger.Info("pruned", "file", p)
+		res.Pruned = append(res.Pruned, p)
+	}
+}
+
+// isGenerated reports whether the file p of fsys carries the generated marker ahead of its package clause.
+func isGenerated(fsys FS, p string) (bool, error) {
+	data, err := fsys.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		l := s.Text()
+		if strings.HasPrefix(l, generatedPrefix) {
+			return true, nil
+		}
+		if strings.HasPrefix(l, "package ") {
+			break
+		}
+	}
+
+	return false, s.Err()
+}
+
+// Reason classifies the cause of the failure in terms a user can act on.
+func (f Failure) Reason() string {
+	if f.Phase == PhaseWrite {
+		switch {
+		case errors.Is(f.Err, syscall.EROFS):
+			return "read-only file system"
+		case errors.Is(f.Err, syscall.ENOSPC):
+			return "no space left on device"
+		case errors.Is(f.Err, fs.ErrPermission):
+			return "permission denied"
+		case errors.Is(f.Err, fs.ErrNotExist):
+			return "output directory does not exist"
+		}
+	}
+
+	return f.Err.Error()
+}
+
+func (f Failure) Error() string {
+	var parts []string
+	if f.Element != "" {
+		parts = append(parts, "<"+f.Element+">")
+	}
+	if f.Path != "" {
+		parts = append(parts, f.Path)
+	}
+	parts = append(parts, string(f.Phase), f.Reason())
+
+	return strings.Join(parts, ": ")
+}
+
+// MarshalJSON encodes f as an object with the element, file and phase of the failure, those that are known, and its
+// message.
+func (f Failure) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Element string `json:"element,omitempty"`
+		File    string `json:"file,omitempty"`
+		Phase   Phase  `json:"phase,omitempty"`
+		Message string `json:"message"`
+	}{f.Element, f.Path, f.Phase, f.Reason()})
+}
+
+func (f Failure) Unwrap() error {
+	return f.Err
+}
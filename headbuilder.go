@@ -0,0 +1,68 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// headBuilderFile is the name of the file declaring HeadBuilder, which assembles the boilerplate children of a <head>
+// element from the meta and link helpers.
+const headBuilderFile = "head_builder.go"
+
+// templHeadBuilder is the data of the template that declares HeadBuilder: the elements it composes, besides the <meta>
+// and <link> elements of the elemHelpers.
+type templHeadBuilder struct {
+	templTarget
+
+	Head, Title, Script templElem
+}
+
+// generateHeadBuilder renders with base HeadBuilder into headBuilderFile, and writes or diffs it according to opts,
+// provided that the planned elements include <head>, <title> and a <script> with a URL src and a string defer, and
+// that the helpers of <meta> and <link> are generated.
+func generateHeadBuilder(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	for _, k := range []string{"head", "title", "script"} {
+		if _, ok := planned[k]; !ok {
+			return
+		}
+	}
+	for _, k := range []string{"meta", "link"} {
+		if e, ok := planned[k]; !ok || !e.hasHelperAttrs(elemHelpers[k]) {
+			return
+		}
+	}
+	if !planned["script"].hasHelperAttrs(elemHelper{Attrs: map[string]string{"defer": "string", "src": "URL"}}) {
+		return
+	}
+
+	data := templHeadBuilder{
+		templTarget: target,
+		Head:        planned["head"],
+		Title:       planned["title"],
+		Script:      planned["script"],
+	}
+	generateShared(base, "headbuilder", headBuilderFile, data, opts, res, produced)
+}
@@ -0,0 +1,102 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// elemHelper is a file of convenience constructors built on the wrapper of an element, generated along with the
+// element provided that it has the attributes Attrs, keyed by name, with the Go types they map to. Template names the
+// template that renders File for the element, and Decls are the identifiers it declares.
+type elemHelper struct {
+	File, Template string
+	Attrs          map[string]string
+	Decls          []string
+}
+
+// elemHelpers maps the elements that have convenience constructors to the files declaring them.
+var elemHelpers = map[string]elemHelper{
+	"link": {
+		File:     "link_helpers.go",
+		Template: "linkhelpers",
+		Attrs:    map[string]string{"as": "LinkAs", "crossorigin": "CrossOrigin", "href": "URL", "rel": "LinkRel"},
+		Decls: []string{
+			"LinkStylesheet", "LinkPreload", "LinkModulePreload", "LinkPreconnect", "LinkIcon", "LinkCanonical",
+		},
+	},
+	"meta": {
+		File:     "meta_helpers.go",
+		Template: "metahelpers",
+		Attrs:    map[string]string{"charset": "Charset", "content": "string", "http-equiv": "HTTPEquiv", "name": "string"},
+		Decls:    []string{"MetaCharset", "MetaName", "MetaDescription", "MetaViewport", "MetaHTTPEquiv", "MetaRefresh"},
+	},
+	"template": {
+		File:     "template_helpers.go",
+		Template: "tmplhelpers",
+		Attrs:    map[string]string{"shadowrootmode": "ShadowRootMode"},
+		Decls:    []string{"TemplateHTML", "ShadowRoot"},
+	},
+}
+
+// hasHelperAttrs reports whether the element e has the attributes of the elemHelper h.
+func (e templElem) hasHelperAttrs(h elemHelper) bool {
+	types := make(map[string]string)
+	for _, a := range e.ElemAttrs() {
+		types[a.JS] = a.Type
+	}
+	for name, t := range h.Attrs {
+		if types[name] != t {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Field returns the name of the props field of the attribute name of e, which must have it.
+func (e templElem) Field(name string) string {
+	for _, a := range e.ElemAttrs() {
+		if a.JS == name {
+			return a.Name
+		}
+	}
+
+	return ""
+}
+
+// generateHelpers renders with base the elemHelpers of the planned elements, and writes or diffs them according to
+// opts.
+func generateHelpers(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	for _, k := range tags {
+		h, ok := elemHelpers[k]
+		if !ok || !planned[k].hasHelperAttrs(h) {
+			continue
+		}
+		e := planned[k]
+		e.templTarget = target
+		generateShared(base, h.Template, h.File, e, opts, res, produced)
+	}
+}
@@ -0,0 +1,307 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlNode is an element or, if Tag is empty, a text node of a parsed HTML fragment.
+type htmlNode struct {
+	Tag      string
+	Attrs    []html.Attribute
+	Text     string
+	Children []*htmlNode
+}
+
+// parseHTML parses the HTML fragment read from r into its top-level nodes, as the content of a <body> element, so that
+// omitted end tags, void elements and the raw text of <script> and <style> follow the rules of HTML. Attribute names
+// in a namespace, such as xlink:href, keep their prefix; comments and doctypes are dropped.
+func parseHTML(r io.Reader) ([]*htmlNode, error) {
+	parsed, err := html.ParseFragment(r, &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*htmlNode
+	for _, n := range parsed {
+		if hn := newHTMLNode(n); hn != nil {
+			nodes = append(nodes, hn)
+		}
+	}
+
+	return nodes, nil
+}
+
+// newHTMLNode returns the htmlNode of the parsed element or text node n, or nil if n is of another kind.
+func newHTMLNode(n *html.Node) *htmlNode {
+	switch n.Type {
+	case html.TextNode:
+		return &htmlNode{Text: n.Data}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	hn := &htmlNode{Tag: n.Data}
+	for _, a := range n.Attr {
+		if a.Namespace != "" {
+			a.Key = a.Namespace + ":" + a.Key
+		}
+		hn.Attrs = append(hn.Attrs, html.Attribute{Key: a.Key, Val: a.Val})
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if ch := newHTMLNode(c); ch != nil {
+			hn.Children = append(hn.Children, ch)
+		}
+	}
+
+	return hn
+}
+
+// htmlConverter translates parsed HTML into calls to the element constructors of a generated package, recording the
+// markup it cannot translate as problems. If jsx is set, text has already been trimmed by the JSX rules and is kept
+// as it is.
+type htmlConverter struct {
+	jsx      bool
+	pkg      string
+	table    map[string]Desc
+	namer    *Namer
+	groups   map[string]*templGroup
+	basic    []templAttr
+	problems []string
+}
+
+// convert translates the parsed nodes into Go expressions calling the constructors of the package pkg, whose elements
+// are described by table and groups and named by namer. It returns the formatted expressions, one per top-level node,
+// and the markup that was left out because it could not be translated.
+func convert(nodes []*htmlNode, jsx bool, pkg string, table map[string]Desc, groups map[string]*templGroup, namer *Namer) ([]byte, []string, error) {
+	c := &htmlConverter{jsx: jsx, pkg: pkg, table: table, namer: namer, groups: groups}
+	for _, a := range basicAttrs {
+		c.basic = append(c.basic, newTemplAttr(a, defaultNamer))
+	}
+
+	var out []byte
+	for _, n := range nodes {
+		x, ok := c.expr(n, false)
+		if !ok {
+			continue
+		}
+		src, err := format.Source([]byte("package p\n\nvar _ = " + x + "\n"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid Go generated for <%s>: %v", n.Tag, err)
+		}
+		if len(out) > 0 {
+			out = append(out, '\n')
+		}
+		out = append(out, strings.TrimPrefix(string(src), "package p\n\nvar _ = ")...)
+	}
+
+	return out, c.problems, nil
+}
+
+// expr returns the Go expression for n, or false if n is left out. Runs of white space in text are collapsed as a
+// browser renders them unless pre is set.
+func (c *htmlConverter) expr(n *htmlNode, pre bool) (string, bool) {
+	if n.Tag == "" {
+		t := n.Text
+		if !pre && !c.jsx {
+			if strings.TrimSpace(t) == "" {
+				return "", false
+			}
+			t = collapseSpace(t)
+		}
+		return c.pkg + ".S(" + strconv.Quote(t) + ")", true
+	}
+
+	d, ok := c.table[n.Tag]
+	if !ok {
+		c.problems = append(c.problems, fmt.Sprintf("<%s> is not a known element", n.Tag))
+		return "", false
+	}
+	e := newTemplElem(n.Tag, d, c.namer, c.groups)
+	e.Basic = c.basic
+
+	byJS := make(map[string]templAttr)
+	for _, a := range e.AllAttrs() {
+		byJS[strings.ToLower(a.JS)] = a
+	}
+	byJS["class"] = byJS["classname"]
+
+	var fields []string
+	for _, a := range n.Attrs {
+		if v, ok := c.attrValue(n.Tag, byJS[a.Key], a); ok {
+			fields = append(fields, byJS[a.Key].Name+": "+v)
+		}
+	}
+	props := "nil"
+	if len(fields) > 0 {
+		props = "&" + c.pkg + "." + e.Props + "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	pre = pre || n.Tag == "pre" || n.Tag == "textarea"
+	var children []string
+	for _, ch := range n.Children {
+		if x, ok := c.expr(ch, pre); ok {
+			children = append(children, x)
+		}
+	}
+	if len(children) == 0 {
+		return c.pkg + "." + e.Upper + "(" + props + ")", true
+	}
+
+	return c.pkg + "." + e.Upper + "(" + props + ",\n" + strings.Join(children, ",\n") + ",\n)", true
+}
+
+// attrValue returns the Go literal for the value of the attribute a of a <tag> element, whose props field is f, or
+// false if a cannot be translated.
+func (c *htmlConverter) attrValue(tag string, f templAttr, a html.Attribute) (string, bool) {
+	if f.Typed() {
+		return c.pkg + "." + f.Type + "(" + strconv.Quote(a.Val) + ")", true
+	}
+
+	var ok bool
+	switch f.Type {
+	case "string":
+		return strconv.Quote(a.Val), true
+	case "bool":
+		// The presence of a boolean attribute makes it true, whatever its value.
+		return "true", true
+	case "int":
+		_, err := strconv.Atoi(a.Val)
+		ok = err == nil
+	case "float64":
+		_, err := strconv.ParseFloat(a.Val, 64)
+		ok = err == nil
+	case "":
+		c.problems = append(c.problems, fmt.Sprintf("<%s> has no attribute %s", tag, a.Key))
+		return "", false
+	}
+	if !ok {
+		c.problems = append(c.problems, fmt.Sprintf("<%s> attribute %s=%q cannot be translated to %s", tag, a.Key, a.Val, f.Type))
+		return "", false
+	}
+
+	return a.Val, true
+}
+
+// collapseSpace replaces every run of white space in s with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
+
+// runHTML2Go runs the convert command, also called html2go, with the arguments args, converting HTML, or JSX with
+// -jsx, to calls to the constructors of the package described by the flags. It returns the exit status.
+func runHTML2Go(fs *flag.FlagSet, args []string) int {
+	jsx := fs.Bool("jsx", false, "read JSX instead of HTML, reporting the expressions and components it cannot translate")
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	spec, table, groups, err := loadTable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+
+	var src []byte
+	switch fs.NArg() {
+	case 0:
+		src, err = io.ReadAll(os.Stdin)
+	case 1:
+		src, err = os.ReadFile(fs.Arg(0))
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	var nodes []*htmlNode
+	var problems []string
+	if *jsx {
+		nodes, problems, err = parseJSX(string(src))
+	} else {
+		nodes, err = parseHTML(bytes.NewReader(src))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "html2go: %v\n", err)
+		return exitConvert
+	}
+
+	namer := NewNamer(spec.Naming)
+	tgroups, failures := newTemplGroups(groups, namer)
+	if len(failures) > 0 {
+		return reportFailures(failures)
+	}
+	pkg := spec.Package
+	if pkg == "" {
+		pkg = defaultPackage
+	}
+
+	out, unknown, err := convert(nodes, *jsx, pkg, table, tgroups, namer)
+	problems = append(problems, unknown...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "html2go: %v\n", err)
+		return exitConvert
+	}
+	os.Stdout.Write(out)
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "html2go: %s\n", p)
+		}
+		return exitConvert
+	}
+
+	return 0
+}
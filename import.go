@@ -0,0 +1,110 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// importElem returns the spec entry of the element k whose hand-written props struct props has the fields fields,
+// naming its type and attributes with overrides only where namer would choose differently.
+func importElem(k, props string, fields []propsField, namer *Namer) Desc {
+	var d Desc
+	if upper := strings.TrimSuffix(strings.TrimPrefix(props, "_"), "Props"); upper != namer.Name(k) {
+		d.Override = upper
+	}
+	for _, f := range fields {
+		if f.JS == "" {
+			continue
+		}
+		a := Attr{Name: f.JS}
+		if f.Name != namer.Name(f.JS) {
+			a.Override = f.Name
+		}
+		if f.Type != "string" {
+			a.Type = f.Type
+		}
+		d.Attributes = append(d.Attributes, a)
+	}
+
+	return d
+}
+
+// runImport runs the import command with the arguments args, printing a spec whose elements are equivalent to the
+// hand-written *_elem.go files of the directory it names. It returns the exit status.
+func runImport(fs *flag.FlagSet, args []string) int {
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	spec, _, groups, err := loadTable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+
+	namer := NewNamer(spec.Naming)
+	tgroups, failures := newTemplGroups(groups, namer)
+	if len(failures) > 0 {
+		return reportFailures(failures)
+	}
+	files, err := handWrittenFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	out := Spec{Elements: make(map[string]Desc)}
+	for _, path := range files {
+		props, _, fields, err := propsFields(path, tgroups)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFormat
+		}
+		if props == "" {
+			fmt.Fprintf(os.Stderr, "%s: no props struct, skipped\n", path)
+			continue
+		}
+		k := strings.ReplaceAll(strings.TrimSuffix(filepath.Base(path), "_elem.go"), "_", "-")
+		out.Elements[k] = importElem(k, props, fields, namer)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	return 0
+}
@@ -0,0 +1,265 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// interactiveFlags are the flags set by the interactive session rather than carried over from the command line.
+var interactiveFlags = map[string]bool{
+	"i": true, "only": true, "skip": true, "strict": true, "experimental": true, "stubs": true, "tests": true,
+	"a11y-tests": true,
+}
+
+// session is the state of an interactive selection of the elements and options of a generation.
+type session struct {
+	tags       []string
+	categories []string
+	byCategory map[string][]string
+	selected   map[string]bool
+
+	strict, experimental, stubs, a11yTests bool
+	tests                                  string
+}
+
+// runInteractive lets the user choose, on standard input, the elements and options of a generation, and prints the
+// equivalent generate command line, keeping the flags set in fs. It returns the exit status.
+func runInteractive(fs *flag.FlagSet) int {
+	only0, skip0 := *only, *skip
+	*only, *skip = "", ""
+	_, table, _, err := loadTable()
+	*only, *skip = only0, skip0
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+	current, err := selectElements(table, only0, skip0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	s := &session{
+		byCategory:   make(map[string][]string),
+		selected:     make(map[string]bool),
+		strict:       *strict,
+		experimental: *experimental,
+		stubs:        *stubs,
+		a11yTests:    *a11yTests,
+		tests:        *tests,
+	}
+	for k, d := range table {
+		if d.HandWritten && !*all {
+			continue
+		}
+		s.tags = append(s.tags, k)
+		c := elementCategory(k)
+		if s.byCategory[c] == nil {
+			s.categories = append(s.categories, c)
+		}
+		s.byCategory[c] = append(s.byCategory[c], k)
+		if _, ok := current[k]; ok {
+			s.selected[k] = true
+		}
+	}
+	sort.Strings(s.tags)
+	sort.Strings(s.categories)
+	for _, tags := range s.byCategory {
+		sort.Strings(tags)
+	}
+
+	if !s.loop(os.Stdin, os.Stderr) {
+		return 0
+	}
+	fmt.Println(s.commandLine(fs))
+
+	return 0
+}
+
+// loop reads commands from r, showing the state and prompts on w, until the user is done, when it returns true, or
+// quits, when it returns false. The end of the input counts as done.
+func (s *session) loop(r io.Reader, w io.Writer) bool {
+	in := bufio.NewScanner(r)
+	for {
+		s.show(w)
+		fmt.Fprint(w, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(w)
+			return true
+		}
+
+		for _, cmd := range strings.Fields(in.Text()) {
+			switch cmd {
+			case "d":
+				return true
+			case "q":
+				return false
+			case "s":
+				s.strict = !s.strict
+			case "x":
+				s.experimental = !s.experimental
+			case "b":
+				s.stubs = !s.stubs
+			case "y":
+				s.a11yTests = !s.a11yTests
+			case "t":
+				s.tests = map[string]string{TestsEach: TestsTable, TestsTable: TestsBoth}[s.tests]
+				if s.tests == "" {
+					s.tests = TestsEach
+				}
+			case "l":
+				fmt.Fprintf(w, "selected: %s\n", strings.Join(s.selectedTags(), " "))
+			case "a":
+				s.toggle(s.tags)
+			default:
+				var n int
+				if _, err := fmt.Sscanf(cmd, "%d", &n); err == nil && n >= 1 && n <= len(s.categories) {
+					s.toggle(s.byCategory[s.categories[n-1]])
+				} else if s.known(cmd) {
+					s.selected[cmd] = !s.selected[cmd]
+				} else {
+					fmt.Fprintf(w, "unknown command or element %q\n", cmd)
+				}
+			}
+		}
+	}
+}
+
+// show writes the categories with the number of their elements selected, and the options, to w.
+func (s *session) show(w io.Writer) {
+	fmt.Fprintln(w, "\nCategories (number toggles the category, a tag toggles the element, a toggles all):")
+	for i, c := range s.categories {
+		var n int
+		for _, k := range s.byCategory[c] {
+			if s.selected[k] {
+				n++
+			}
+		}
+		fmt.Fprintf(w, "  %2d %s %-12s %d/%d\n", i+1, mark(n == len(s.byCategory[c])), c, n, len(s.byCategory[c]))
+	}
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintf(w, "   s %s strict (drop non-standard attributes)\n", mark(s.strict))
+	fmt.Fprintf(w, "   x %s experimental elements and attributes\n", mark(s.experimental))
+	fmt.Fprintf(w, "   b %s stubs for non-js builds\n", mark(s.stubs))
+	fmt.Fprintf(w, "   y %s accessibility test\n", mark(s.a11yTests))
+	fmt.Fprintf(w, "   t     tests: %s\n", s.tests)
+	fmt.Fprintln(w, "l lists the selected elements, d prints the command line, q quits.")
+}
+
+// mark returns the check box of an option that is on or off.
+func mark(on bool) string {
+	if on {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// known reports whether k is one of the elements that can be selected.
+func (s *session) known(k string) bool {
+	i := sort.SearchStrings(s.tags, k)
+	return i < len(s.tags) && s.tags[i] == k
+}
+
+// toggle selects all of tags unless they all are already selected, in which case it deselects them.
+func (s *session) toggle(tags []string) {
+	all := true
+	for _, k := range tags {
+		all = all && s.selected[k]
+	}
+	for _, k := range tags {
+		s.selected[k] = !all
+	}
+}
+
+// selectedTags returns the selected elements, sorted.
+func (s *session) selectedTags() []string {
+	var tags []string
+	for _, k := range s.tags {
+		if s.selected[k] {
+			tags = append(tags, k)
+		}
+	}
+	return tags
+}
+
+// commandLine returns the generate command line equivalent to the session, keeping the flags set in fs that the
+// session does not choose. The selection is given by -only or -skip, whichever is shorter.
+func (s *session) commandLine(fs *flag.FlagSet) string {
+	args := []string{path.Base(os.Args[0]), "generate"}
+	set := make(map[string]bool)
+	keep := func(f *flag.Flag) {
+		if !interactiveFlags[f.Name] && !set[f.Name] {
+			set[f.Name] = true
+			args = append(args, "-"+f.Name+"="+shellQuote(f.Value.String()))
+		}
+	}
+	commandLine.Visit(keep)
+	fs.Visit(keep)
+
+	selected := s.selectedTags()
+	var skipped []string
+	for _, k := range s.tags {
+		if !s.selected[k] {
+			skipped = append(skipped, k)
+		}
+	}
+	switch {
+	case len(skipped) == 0:
+	case len(selected) <= len(skipped):
+		args = append(args, "-only="+strings.Join(selected, ","))
+	default:
+		args = append(args, "-skip="+strings.Join(skipped, ","))
+	}
+
+	for _, o := range []struct {
+		name string
+		on   bool
+	}{{"strict", s.strict}, {"experimental", s.experimental}, {"stubs", s.stubs}, {"a11y-tests", s.a11yTests}} {
+		if o.on {
+			args = append(args, "-"+o.name)
+		}
+	}
+	if s.tests != TestsEach {
+		args = append(args, "-tests="+s.tests)
+	}
+
+	return strings.Join(args, " ")
+}
+
+// shellQuote returns v quoted for a POSIX shell if it contains characters the shell would interpret.
+func shellQuote(v string) string {
+	if v != "" && strings.Trim(v, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=,@+") == "" {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
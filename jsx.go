@@ -0,0 +1,390 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// jsxParser parses the subset of JSX that can be translated to calls to the element constructors: elements with
+// static attributes, fragments, text, and string literals in braces. Everything else is recorded as a problem and
+// left out.
+type jsxParser struct {
+	src      string
+	pos      int
+	problems []string
+}
+
+// parseJSX parses the JSX in src into its top-level nodes. Fragments are replaced by their children, and text is
+// trimmed the way JSX trims it. The constructs that cannot be translated, such as components, embedded expressions
+// and spread attributes, are returned as problems.
+func parseJSX(src string) ([]*htmlNode, []string, error) {
+	p := &jsxParser{src: src}
+	nodes, err := p.children("", true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, nil, p.errorf("unexpected closing tag")
+	}
+
+	return nodes, p.problems, nil
+}
+
+// children parses nodes up to the next closing tag, which is left unread, or to the end of the input. Only the top
+// level, at which top is set, may end with the input; otherwise the element tag is reported as not closed.
+func (p *jsxParser) children(tag string, top bool) ([]*htmlNode, error) {
+	var nodes []*htmlNode
+	for p.pos < len(p.src) {
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "</"):
+			return nodes, nil
+		case p.src[p.pos] == '<':
+			n, err := p.element()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case n == nil:
+			case n.Tag == "":
+				// A fragment contributes its children.
+				nodes = append(nodes, n.Children...)
+			default:
+				nodes = append(nodes, n)
+			}
+		case p.src[p.pos] == '{':
+			line := p.line()
+			x, err := p.braces()
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := jsxString(x); ok {
+				nodes = append(nodes, &htmlNode{Text: s})
+			} else if !jsxComment(x) {
+				p.problemf(line, "expression {%s} cannot be translated", x)
+			}
+		default:
+			end := strings.IndexAny(p.src[p.pos:], "<{")
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			if t := jsxText(p.src[p.pos : p.pos+end]); t != "" {
+				nodes = append(nodes, &htmlNode{Text: t})
+			}
+			p.pos += end
+		}
+	}
+	if !top {
+		return nil, p.errorf("<%s> is not closed", tag)
+	}
+
+	return nodes, nil
+}
+
+// element parses an element or fragment starting at the '<' under p.pos. It returns a node with an empty Tag for a
+// fragment, and nil for a component, which is recorded as a problem.
+func (p *jsxParser) element() (*htmlNode, error) {
+	line := p.line()
+	p.pos++
+	name := p.name()
+	n := &htmlNode{Tag: strings.ToLower(name)}
+	component := name != "" && (unicode.IsUpper(rune(name[0])) || strings.Contains(name, "."))
+
+	for {
+		p.space()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("<%s> is not closed", name)
+		}
+		if strings.HasPrefix(p.src[p.pos:], "/>") {
+			p.pos += 2
+			return p.done(n, name, component, line), nil
+		}
+		if p.src[p.pos] == '>' {
+			p.pos++
+			break
+		}
+		if err := p.attr(n, line); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if n.Children, err = p.children(name, false); err != nil {
+		return nil, err
+	}
+	p.pos += len("</")
+	if closing := p.name(); closing != name {
+		return nil, p.errorf("<%s> is closed by </%s>", name, closing)
+	}
+	p.space()
+	if p.pos >= len(p.src) || p.src[p.pos] != '>' {
+		return nil, p.errorf("malformed closing tag </%s", name)
+	}
+	p.pos++
+
+	return p.done(n, name, component, line), nil
+}
+
+// done returns the parsed node n, or nil if it is a component.
+func (p *jsxParser) done(n *htmlNode, name string, component bool, line int) *htmlNode {
+	if component {
+		p.problemf(line, "component <%s> cannot be translated", name)
+		return nil
+	}
+	return n
+}
+
+// attr parses an attribute of n. Attributes whose value is an expression other than a literal are recorded as
+// problems, and those set to {false} are left out like absent boolean attributes.
+func (p *jsxParser) attr(n *htmlNode, line int) error {
+	if p.src[p.pos] == '{' {
+		x, err := p.braces()
+		if err != nil {
+			return err
+		}
+		p.problemf(line, "spread attribute {%s} cannot be translated", x)
+		return nil
+	}
+
+	name := p.name()
+	if name == "" {
+		return p.errorf("malformed attribute in <%s>", n.Tag)
+	}
+	a := html.Attribute{Key: jsxAttrName(name), Val: "true"}
+	p.space()
+	if p.pos < len(p.src) && p.src[p.pos] == '=' {
+		p.pos++
+		p.space()
+		switch {
+		case p.pos >= len(p.src):
+			return p.errorf("attribute %s has no value", name)
+		case p.src[p.pos] == '"' || p.src[p.pos] == '\'':
+			q := p.src[p.pos]
+			end := strings.IndexByte(p.src[p.pos+1:], q)
+			if end < 0 {
+				return p.errorf("unterminated value of attribute %s", name)
+			}
+			a.Val = decodeEntities(p.src[p.pos+1 : p.pos+1+end])
+			p.pos += end + 2
+		case p.src[p.pos] == '{':
+			x, err := p.braces()
+			if err != nil {
+				return err
+			}
+			x = strings.TrimSpace(x)
+			if s, ok := jsxString(x); ok {
+				a.Val = s
+			} else if _, err := strconv.ParseFloat(x, 64); err == nil || x == "true" {
+				a.Val = x
+			} else if x == "false" {
+				return nil
+			} else {
+				p.problemf(line, "attribute %s={%s} cannot be translated", name, x)
+				return nil
+			}
+		default:
+			return p.errorf("attribute %s has no value", name)
+		}
+	}
+	n.Attrs = append(n.Attrs, a)
+
+	return nil
+}
+
+// braces returns the source between the '{' under p.pos and its matching '}', skipping braces in string literals.
+func (p *jsxParser) braces() (string, error) {
+	start := p.pos
+	depth := 0
+	for i := p.pos; i < len(p.src); i++ {
+		switch c := p.src[i]; c {
+		case '"', '\'', '`':
+			end := strings.IndexByte(p.src[i+1:], c)
+			if end < 0 {
+				return "", p.errorf("unterminated string")
+			}
+			i += end + 1
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos = i + 1
+				return p.src[start+1 : i], nil
+			}
+		}
+	}
+
+	return "", p.errorf("unterminated {")
+}
+
+// name reads a tag or attribute name.
+func (p *jsxParser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune("_-.:$", c) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// space skips white space.
+func (p *jsxParser) space() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// line returns the line number of p.pos.
+func (p *jsxParser) line() int {
+	return strings.Count(p.src[:p.pos], "\n") + 1
+}
+
+func (p *jsxParser) problemf(line int, format string, args ...interface{}) {
+	p.problems = append(p.problems, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+func (p *jsxParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: "+format, append([]interface{}{p.line()}, args...)...)
+}
+
+// jsxAttrName maps the React name of an attribute to its HTML name where the two differ by more than case.
+func jsxAttrName(name string) string {
+	switch name {
+	case "htmlFor":
+		return "for"
+	case "className":
+		return "class"
+	}
+	return strings.ToLower(name)
+}
+
+// jsxString returns the value of x if it is a single string literal.
+func jsxString(x string) (string, bool) {
+	x = strings.TrimSpace(x)
+	if len(x) < 2 || !strings.ContainsRune("\"'`", rune(x[0])) || x[len(x)-1] != x[0] ||
+		strings.ContainsRune(x[1:len(x)-1], rune(x[0])) {
+		return "", false
+	}
+	if x[0] == '`' && strings.Contains(x, "${") {
+		return "", false
+	}
+	return x[1 : len(x)-1], true
+}
+
+// jsxComment reports whether x is a comment.
+func jsxComment(x string) bool {
+	x = strings.TrimSpace(x)
+	return strings.HasPrefix(x, "/*") && strings.HasSuffix(x, "*/")
+}
+
+// jsxText applies the JSX white space rules to the text t: lines are trimmed, blank lines are dropped, and the
+// remaining lines are joined by single spaces. Text on a single line is kept as written.
+func jsxText(t string) string {
+	if !strings.Contains(t, "\n") {
+		return decodeEntities(t)
+	}
+
+	var lines []string
+	for i, l := range strings.Split(t, "\n") {
+		if i > 0 {
+			l = strings.TrimLeft(l, " \t\r")
+		}
+		if i < strings.Count(t, "\n") {
+			l = strings.TrimRight(l, " \t\r")
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return decodeEntities(strings.Join(lines, " "))
+}
+
+// decodeEntities replaces the HTML character references in s by the characters they stand for. Unknown references
+// are kept as written.
+func decodeEntities(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '&')
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		end := strings.IndexByte(s, ';')
+		if end < 0 {
+			break
+		}
+		if r, ok := entity(s[1:end]); ok {
+			b.WriteString(r)
+		} else {
+			b.WriteString(s[:end+1])
+		}
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+
+	return b.String()
+}
+
+// entity returns the text of the character reference &ref;.
+func entity(ref string) (string, bool) {
+	switch ref {
+	case "amp":
+		return "&", true
+	case "lt":
+		return "<", true
+	case "gt":
+		return ">", true
+	case "quot":
+		return `"`, true
+	case "apos":
+		return "'", true
+	}
+	if strings.HasPrefix(ref, "#") {
+		var n int64
+		var err error
+		if strings.HasPrefix(ref, "#x") || strings.HasPrefix(ref, "#X") {
+			n, err = strconv.ParseInt(ref[2:], 16, 32)
+		} else {
+			n, err = strconv.ParseInt(ref[1:], 10, 32)
+		}
+		return string(rune(n)), err == nil
+	}
+	r, ok := xml.HTMLEntity[ref]
+	return r, ok
+}
@@ -0,0 +1,245 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lintProblem is a difference between the props of a hand-written element and its spec entry.
+type lintProblem struct {
+	Pos     token.Position
+	Tag     string
+	Message string
+}
+
+func (p lintProblem) String() string {
+	return fmt.Sprintf("%s: <%s>: %s", p.Pos, p.Tag, p.Message)
+}
+
+// propsField is a field of a hand-written props struct.
+type propsField struct {
+	Name, JS, Type string
+	Pos            token.Position
+}
+
+// propsFields returns the name, position and fields of the props struct declared in the Go source file path: the struct
+// type whose name starts with _ and ends with Props. The name is empty if there is none. Embedded attribute groups,
+// named by the types of groups, contribute their attributes; other embedded fields, such as *BasicHTMLElement, are
+// skipped.
+func propsFields(path string, groups map[string]*templGroup) (string, token.Position, []propsField, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return "", token.Position{}, nil, err
+	}
+
+	byType := make(map[string]*templGroup)
+	for _, g := range groups {
+		byType[g.Type] = g
+	}
+	str := func(n ast.Node) string {
+		var b bytes.Buffer
+		printer.Fprint(&b, fset, n)
+		return b.String()
+	}
+	for _, d := range f.Decls {
+		d, ok := d.(*ast.GenDecl)
+		if !ok || d.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range d.Specs {
+			s := s.(*ast.TypeSpec)
+			st, ok := s.Type.(*ast.StructType)
+			if !ok || !strings.HasPrefix(s.Name.Name, "_") || !strings.HasSuffix(s.Name.Name, "Props") {
+				continue
+			}
+
+			var fields []propsField
+			for _, fld := range st.Fields.List {
+				pos := fset.Position(fld.Pos())
+				if len(fld.Names) == 0 {
+					if g, ok := byType[strings.TrimPrefix(str(fld.Type), "*")]; ok {
+						for _, a := range g.Attrs {
+							fields = append(fields, propsField{Name: a.Name, JS: a.JS, Type: a.Type, Pos: pos})
+						}
+					}
+					continue
+				}
+				var js string
+				if fld.Tag != nil {
+					tag, err := strconv.Unquote(fld.Tag.Value)
+					if err == nil {
+						js = reflect.StructTag(tag).Get("js")
+					}
+				}
+				for _, n := range fld.Names {
+					fields = append(fields, propsField{Name: n.Name, JS: js, Type: str(fld.Type), Pos: pos})
+				}
+			}
+			return s.Name.Name, fset.Position(s.Pos()), fields, nil
+		}
+	}
+
+	return "", token.Position{}, nil, nil
+}
+
+// lintElem compares the props fields of the element k, declared at pos, with the attributes e its spec entry
+// generates.
+func lintElem(k string, e templElem, fields []propsField, pos token.Position) []lintProblem {
+	var problems []lintProblem
+	byJS := make(map[string]propsField)
+	byName := make(map[string]propsField)
+	for _, f := range fields {
+		if f.JS != "" {
+			byJS[f.JS] = f
+		}
+		byName[f.Name] = f
+	}
+
+	known := make(map[string]bool)
+	for _, a := range e.ElemAttrs() {
+		known[a.JS] = true
+		f, ok := byJS[a.JS]
+		switch {
+		case ok && f.Name != a.Name:
+			problems = append(problems, lintProblem{f.Pos, k, fmt.Sprintf("attribute %s is field %s, the spec names it %s", a.JS, f.Name, a.Name)})
+		case ok && f.Type != a.Type:
+			problems = append(problems, lintProblem{f.Pos, k, fmt.Sprintf("attribute %s has type %s, the spec has %s", a.JS, f.Type, a.Type)})
+		case !ok:
+			if f, ok := byName[a.Name]; ok {
+				problems = append(problems, lintProblem{f.Pos, k, fmt.Sprintf("field %s has js tag %q, the spec has %q", a.Name, f.JS, a.JS)})
+				known[f.JS] = true
+			} else {
+				problems = append(problems, lintProblem{pos, k, fmt.Sprintf("attribute %s (%s %s) is missing", a.JS, a.Name, a.Type)})
+			}
+		}
+	}
+	for _, f := range fields {
+		if f.JS != "" && !known[f.JS] {
+			problems = append(problems, lintProblem{f.Pos, k, fmt.Sprintf("attribute %s (field %s) is not in the spec", f.JS, f.Name)})
+		}
+	}
+
+	return problems
+}
+
+// handWrittenFiles returns the *_elem.go files of dir that were not generated, in name order.
+func handWrittenFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_elem.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var hand []string
+	for _, p := range files {
+		gen, err := isGenerated(osFS{}, p)
+		if err != nil {
+			return nil, err
+		}
+		if !gen {
+			hand = append(hand, p)
+		}
+	}
+
+	return hand, nil
+}
+
+// runLint runs the lint command with the arguments args, comparing the hand-written *_elem.go files of the directory
+// it names with the element table. It returns the exit status.
+func runLint(fs *flag.FlagSet, args []string) int {
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	spec, table, groups, err := loadTable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+
+	namer := NewNamer(spec.Naming)
+	tgroups, failures := newTemplGroups(groups, namer)
+	if len(failures) > 0 {
+		return reportFailures(failures)
+	}
+	files, err := handWrittenFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+	tags := make(map[string]string)
+	for k := range table {
+		tags[fileBase(k)] = k
+	}
+
+	var problems []lintProblem
+	for _, path := range files {
+		base := strings.TrimSuffix(filepath.Base(path), "_elem.go")
+		k, ok := tags[base]
+		if !ok {
+			k = base
+		}
+		props, pos, fields, err := propsFields(path, tgroups)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFormat
+		}
+		if props == "" {
+			continue
+		}
+		d, ok := table[k]
+		if !ok {
+			problems = append(problems, lintProblem{pos, k, "no such element in the spec"})
+			continue
+		}
+		problems = append(problems, lintElem(k, newTemplElem(k, d, namer, tgroups), fields, pos)...)
+	}
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return exitLint
+	}
+
+	return 0
+}
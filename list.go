@@ -0,0 +1,144 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+type (
+	// listedElem is an element as printed by the list command.
+	listedElem struct {
+		Tag        string       `json:"tag"`
+		Name       string       `json:"name"`
+		Overridden bool         `json:"overridden,omitempty"`
+		Groups     []string     `json:"groups,omitempty"`
+		Attributes []listedAttr `json:"attributes,omitempty"`
+	}
+
+	// listedAttr is an attribute as printed by the list command. Group names the attribute group it comes from, if
+	// any.
+	listedAttr struct {
+		Name  string `json:"name"`
+		Field string `json:"field"`
+		Type  string `json:"type"`
+		Group string `json:"group,omitempty"`
+	}
+)
+
+// listElems returns the elements of table that would be generated, in tag order, with their names resolved by namer
+// and, if attrs is set, their attributes.
+func listElems(table map[string]Desc, groups map[string]*templGroup, namer *Namer, handWritten, attrs bool) []listedElem {
+	var tags []string
+	for k, d := range table {
+		if !d.HandWritten || handWritten {
+			tags = append(tags, k)
+		}
+	}
+	sort.Strings(tags)
+
+	var elems []listedElem
+	for _, k := range tags {
+		d := table[k]
+		e := newTemplElem(k, d, namer, groups)
+		l := listedElem{Tag: k, Name: e.Upper, Overridden: d.Override != "", Groups: d.Groups}
+		if attrs {
+			for i, g := range e.Groups {
+				for _, a := range g.Attrs {
+					l.Attributes = append(l.Attributes, listedAttr{Name: a.JS, Field: a.Name, Type: a.Type, Group: d.Groups[i]})
+				}
+			}
+			for _, a := range e.Attrs {
+				l.Attributes = append(l.Attributes, listedAttr{Name: a.JS, Field: a.Name, Type: a.Type})
+			}
+		}
+		elems = append(elems, l)
+	}
+
+	return elems
+}
+
+// runList runs the list command with the arguments args, printing the elements of the element table that would be
+// generated. It returns the exit status.
+func runList(fs *flag.FlagSet, args []string) int {
+	attrs := fs.Bool("attrs", false, "also print every attribute with its Go field name and type")
+	asJSON := fs.Bool("json", false, "print the elements as JSON")
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	spec, table, groups, err := loadTable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+
+	namer := NewNamer(spec.Naming)
+	tgroups, failures := newTemplGroups(groups, namer)
+	if len(failures) > 0 {
+		return reportFailures(failures)
+	}
+	elems := listElems(table, tgroups, namer, *all, *attrs)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(elems); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitIO
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, e := range elems {
+		line := e.Tag + "\t" + e.Name
+		if e.Overridden {
+			line += "\t(override)"
+		}
+		fmt.Fprintln(w, line)
+		for _, a := range e.Attributes {
+			line := "  " + a.Name + "\t" + a.Field + "\t" + a.Type
+			if a.Group != "" {
+				line += "\t(group " + a.Group + ")"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	return 0
+}
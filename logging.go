@@ -0,0 +1,76 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// logger receives the progress messages and warnings of the commands: per-file lines at the debug level, summaries
+// at the info level and dropped attributes at the warning level. configureLogger sets it up from -q, -v and
+// -log-format.
+var logger = newLogger(os.Stderr, "text", slog.LevelInfo)
+
+// newLogger returns a logger writing the records at level or above to w, as key=value text without timestamps or, if
+// format is "json", as JSON objects, one per line.
+func newLogger(w io.Writer, format string, level slog.Level) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// configureLogger points logger at standard error with the level selected by -q or -v and the format of -log-format.
+func configureLogger() error {
+	if *logFormat != "text" && *logFormat != "json" {
+		return fmt.Errorf("-log-format %q: want text or json", *logFormat)
+	}
+	if *quiet && *verbose {
+		return fmt.Errorf("-q and -v cannot be combined")
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case *quiet:
+		level = slog.LevelWarn
+	case *verbose:
+		level = slog.LevelDebug
+	}
+	logger = newLogger(os.Stderr, *logFormat, level)
+
+	return nil
+}
@@ -22,624 +22,669 @@
  * SOFTWARE.
  */
 
-package main
+// Package elemental generates the Go wrappers of HTML elements for myitcv.io/react from a catalog describing the
+// elements and their attributes. Generate is the library entry point; Main runs the elemental command, whose main
+// package is github.com/grkuntzmd/elemental/cmd/elemental.
+package elemental
 
 import (
-	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"go/format"
 	"os"
 	"path"
-	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
-	"text/template"
+	"text/tabwriter"
+	"time"
 )
 
 const (
-	primaryTemplate = `
-// Copyright (c) 2018 Paul Jolly <paul@myitcv.org.uk>, all rights reserved.
-// Use of this document is governed by a license found in the LICENSE document.
+	// generatedPrefix starts the marker line that identifies files written by this tool, so that stale outputs can be
+	// told apart from hand-written sources in the same directory.
+	generatedPrefix = "// Code generated by elemental"
 
-package react
+	// defaultHeader is the banner written below the generated marker when no -header file is given.
+	defaultHeader = `// Copyright (c) 2018 Paul Jolly <paul@myitcv.org.uk>, all rights reserved.
+// Use of this document is governed by a license found in the LICENSE document.`
+)
 
-// {{ .Elem }} is the React element definition corresponding to the HTML <{{ .Name }}> element.
-type {{ .Elem }} struct {
-	Element
-}
+type (
+	Desc struct {
+		Override    string   `json:"override,omitempty"`
+		Groups      []string `json:"groups,omitempty"`
+		Attributes  []Attr   `json:"attributes,omitempty"`
+		HandWritten bool     `json:"handWritten,omitempty"`
+
+		// Formerly lists the names the element was generated under before being renamed. Deprecated aliases of the
+		// old type names and a wrapper of the old constructor are generated for each, and should be removed from the
+		// spec after a release.
+		Formerly []string `json:"formerly,omitempty"`
+
+		// Experimental marks elements that browsers support only behind a flag, if at all; they are generated, with a
+		// warning in their documentation, only with -experimental.
+		Experimental bool `json:"experimental,omitempty"`
+	}
 
-// _{{ .Props }} defines the properties for the <{{ .Name }}> element.
-type _{{ .Props }} struct {
-	*BasicHTMLElement
+	Attr struct {
+		Name     string `json:"name"`
+		Override string `json:"override,omitempty"`
+		Type     string `json:"type,omitempty"`
 
-	{{ range .Attrs }}{{ .Name }} {{ .Type }} ` + "`js:\"{{ .JS }}\"`" + `
-	{{ end }}
-}
+		// NonStandard marks attributes that are obsolete or were never standardised, such as datafld or
+		// mozCurrentSampleOffset; -strict drops them.
+		NonStandard bool `json:"nonStandard,omitempty"`
 
-// A creates a new instance of a <{{ .Name }}> element with the provided props and children.
-func {{ .Upper }}(props *{{ .Props }}, children ...Element) *{{ .Elem }} {
-	rProps := &_{{ .Props }}{
-		BasicHTMLElement: newBasicHTMLElement(),
-	}
+		// Experimental marks attributes that browsers support only behind a flag, if at all, such as fetchpriority;
+		// like experimental elements they are generated only with -experimental.
+		Experimental bool `json:"experimental,omitempty"`
 
-	if props != nil {
-		props.assign(rProps)
+		// Compat is a note on browser support added to the documentation of the attribute, such as "Not supported
+		// in Safari < 16."; -bcd fills it in from MDN's browser-compat-data.
+		Compat string `json:"compat,omitempty"`
 	}
 
-	return &{{ .Elem }}{
-		Element: createElement("{{ .Name }}", rProps, children...),
-	}
-}
-`
-	testTemplate = `
-// +build js
+	templElem struct {
+		templTarget
 
-package react_test
+		Elem, Name, Props, Upper string
+		Groups                   []*templGroup
+		Attrs                    []templAttr
 
-import (
-	"testing"
+		// Option is the interface of the options accepted by the element's Opt constructor, and OptionFunc the
+		// unexported function type implementing it for the element's own attributes.
+		Option, OptionFunc string
 
-	"honnef.co/go/js/dom"
+		// Formerly lists the former names of the element, for which deprecated shims are generated.
+		Formerly []string
 
-	"myitcv.io/react"
-	"myitcv.io/react/testutils"
-)
+		// Experimental reports that the element is marked experimental in the spec.
+		Experimental bool
 
-func Test{{ .Elem }}(t *testing.T) {
-	class := "test"
+		// DOMType is the type, in honnef.co/go/js/dom, of the DOM node the element renders.
+		DOMType string
 
-	x := testutils.Wrapper(react.{{ .Upper }}(&react.{{ .Props }}{ClassName: class}))
-	cont := testutils.RenderIntoDocument(x)
+		// Void reports that the element cannot have children, and A11yTested that it is part of the accessibility
+		// test.
+		Void, A11yTested bool
 
-	el := testutils.FindRenderedDOMComponentWithClass(cont, class)
+		// Category is the section of the HTML standard that defines the element; see elementCategories.
+		Category string
 
-	if _, ok := el.(*dom.HTMLAnchorElement); !ok {
-		t.Fatal("Failed to find <{{ .Name }}> element")
+		// RefMethods are the methods of the element's Ref type, generated with -refs; see refMethods.
+		RefMethods []refMethod
+
+		// Override holds the declarations merged from the element's override file, if any.
+		Override *override
 	}
-}
-`
-)
 
-type (
-	Desc struct {
-		Override   string
-		Attributes []Attr
+	// templTarget describes the package the generated files belong to, the banner they carry, and the tool version
+	// and spec digest recorded in their marker. ImportAlias is empty unless Package differs from the last element of
+	// ImportPath. Basic holds the properties of the package's BasicHTMLElement. Stubs reports that non-js stubs
+	// accompany the element files, Refs that the props have a Ref field, and Lint that the constructors check for
+	// accessibility mistakes. Constraint, TestConstraint and StubConstraint are the build constraint lines of the
+	// element, test and stub files, if any.
+	templTarget struct {
+		Package, ImportPath, ImportAlias, Header   string
+		Version, SpecSum                           string
+		Basic                                      []templAttr
+		Stubs, Refs, Lint                          bool
+		Constraint, TestConstraint, StubConstraint string
 	}
 
-	Attr struct {
-		Name     string
-		Override string
-		Type     string
+	templAttr struct {
+		Name, JS, Type, Compat string
+		Experimental           bool
 	}
 
-	templElem struct {
-		Elem, Name, Props, Upper string
-		Attrs                    []templAttr
+	// templGroup is an attribute group generated as a struct embedded in the props of the elements that use it.
+	templGroup struct {
+		Name, Type, Users string
+		Attrs             []templAttr
 	}
 
-	templAttr struct {
-		Name, JS, Type string
+	// templRegistry is the data of the template that declares the registry of the generated elements.
+	templRegistry struct {
+		templTarget
+
+		Elems []templElem
+	}
+
+	// templEvents is the data of the template that declares the event handler interfaces used by the generated
+	// elements, and the comparison of handlers.
+	templEvents struct {
+		templTarget
+
+		Handlers []templHandler
+	}
+
+	// templHandler is an event handler interface: Type handles the DOM event Event.
+	templHandler struct {
+		Type, Event string
+	}
+
+	// templGroups is the data of the template that declares the attribute groups.
+	templGroups struct {
+		templTarget
+
+		Groups []*templGroup
 	}
 )
 
+// version is the elemental release recorded in generated files. Release builds set it with
+// -ldflags "-X main.version=vX.Y.Z".
+var version = "v0.1.0"
+
+// Exit codes distinguish the kinds of failure so that scripts can react to them.
+const (
+	exitOutOfDate = 1 // -check found out-of-date files
+	exitUsage     = 2 // invalid command line or generation options
+	exitSpec      = 3 // an element description is invalid
+	exitFormat    = 4 // a template failed to execute or produced invalid Go source
+	exitIO        = 5 // a file could not be read, written, or deleted
+	exitConvert   = 6 // html2go found markup it cannot translate
+	exitBreaking  = 7 // -fail-on-breaking found breaking changes to the generated API
+	exitLint      = 8 // lint found hand-written elements that disagree with the spec
+)
+
+// commandLine holds the flags of the generate command, which the other commands share. It is private to the package,
+// so that importing it leaves the flags of the importing program alone.
+var commandLine = flag.NewFlagSet("elemental", flag.ContinueOnError)
+
 var (
-	outputDirectory = flag.String("o", ".", "output directory to write the generated Go files")
+	outputDirectory = commandLine.String("o", ".", "output directory to write the generated Go files, or - to write them all to standard output in the txtar format")
+	prune           = commandLine.Bool("prune", false, "delete previously generated files that the current element table no longer produces")
+	dryRun          = commandLine.Bool("dry-run", false, "print unified diffs against the output directory instead of writing any file")
+	verbose         = commandLine.Bool("v", false, "log every file written and report how long generation took and which elements were slowest to render")
+	jsonErrors      = commandLine.Bool("json-errors", false, "also print the failures on standard error as a JSON object with a \"failures\" array of element, file, phase and message")
+	quiet           = commandLine.Bool("q", false, "log only warnings and errors")
+	logFormat       = commandLine.String("log-format", "text", "`format` of the log messages on standard error: text or json")
+	slowest         = commandLine.Int("slowest", 10, "number of slowest elements to report in verbose mode")
+	check           = commandLine.Bool("check", false, "exit non-zero, listing the out-of-date files, if the output directory differs from what would be generated")
+	goImports       = commandLine.Bool("goimports", false, "format the generated files with goimports instead of gofmt")
+	workers         = commandLine.Int("j", runtime.GOMAXPROCS(0), "number of elements to generate concurrently")
+	keepGoing       = commandLine.Bool("keep-going", false, "continue with the remaining elements after an element fails to generate")
+	packageName     = commandLine.String("package", "", "name of the generated `package` (default \""+defaultPackage+"\" or the spec's \"package\")")
+	importPath      = commandLine.String("import-path", "", "import `path` of the generated package (default \""+defaultImportPath+"\" or the spec's \"importPath\")")
+	headerFile      = commandLine.String("header", "", "`file` whose contents replace the copyright banner of the generated files")
+	reservedFile    = commandLine.String("reserved", "", "`file` listing identifiers, one per line, already declared by hand-written code in the target package")
+	all             = commandLine.Bool("all", false, "also generate the elements that are hand-written upstream")
+	overrideDir     = commandLine.String("overrides", "", "`directory` of <tag>_override.go files whose declarations are merged into the generated files")
+	config          = commandLine.String("config", "", "JSON or YAML spec file `path` or URL replacing the built-in element table (- reads standard input)")
+	configSum       = commandLine.String("config-sha256", "", "hex SHA-256 `digest` the -config spec must have, to pin a shared catalog")
+	failOnBreaking  = commandLine.Bool("fail-on-breaking", false, "write nothing and exit non-zero if regenerating would remove or change exported identifiers of the existing files")
+	templateDir     = commandLine.String("templates", "", "`directory` of <name>.tmpl and element/<tag>.tmpl files replacing the embedded templates")
+	compileCheck    = commandLine.Bool("compile-check", false, "build the output directory with go build for GOOS=js after writing, failing with the diagnostics of the elements that do not compile")
+	bcdFile         = commandLine.String("bcd", "", "`file` or URL of MDN browser-compat-data (data.json) whose browser support notes are added to the attribute documentation")
+	minBrowsers     = commandLine.String("min-browsers", "", "browser `baseline`, such as \"chrome 100, safari 15.4\", whose unsupported attributes are dropped (requires -bcd)")
+	experimental    = commandLine.Bool("experimental", false, "also generate the elements and attributes marked experimental, which browsers may support only behind a flag")
+	stubs           = commandLine.Bool("stubs", false, "constrain the generated files to js builds and add <tag>_elem_stub.go placeholders so the package also builds natively")
+	build           = commandLine.String("build", "", "build constraint `expression` of the generated element files, such as \"js && wasm\", or none (default none, or js with -stubs, or the spec's \"build\")")
+	testBuild       = commandLine.String("test-build", "", "build constraint `expression` of the generated tests, or none (default js or the spec's \"testBuild\")")
+	tests           = commandLine.String("tests", TestsEach, "`layout` of the generated tests: each (a test file per element), table (one table-driven test of every element), or both")
+	typedStyle      = commandLine.Bool("typed-style", false, "replace the *CSS style of every element by a generated InlineStyle struct with typed CSS properties")
+	refs            = commandLine.Bool("refs", false, "add to the props of every element a Ref callback receiving its DOM node, typed as in honnef.co/go/js/dom")
+	a11yTests       = commandLine.Bool("a11y-tests", false, "also generate a test running axe-core against every rendered element")
+	a11yLint        = commandLine.Bool("a11y-lint", false, "generate constructors that warn about common accessibility mistakes in builds with the a11ylint tag")
+	only            = commandLine.String("only", "", "comma-separated `tags` of the only elements to generate")
+	skip            = commandLine.String("skip", "", "comma-separated `tags` of elements not to generate")
+	interactive     = commandLine.Bool("i", false, "choose the elements and options interactively, then print the equivalent command line")
+	strict          = commandLine.Bool("strict", false, "drop the attributes marked nonStandard, warning about each element that had any")
+	projectConfig   = commandLine.String("project-config", "", "project configuration `file` whose settings apply to the flags not given on the command line (default the nearest .elemental.yaml or .elemental.yml, or none to disable)")
+	archive         = commandLine.String("archive", "", "write the generated files into the archive `file` (.zip, .tar, .tar.gz or .tgz) instead of the output directory")
+	cacheDirectory  = commandLine.String("cache-dir", "", "`directory` caching the inputs fetched from URLs (default elemental in the user cache directory)")
+	cacheTTL        = commandLine.Duration("cache-ttl", 24*time.Hour, "how long a cached input is used before it is revalidated with the server")
+	offline         = commandLine.Bool("offline", false, "use only cached copies of the inputs given as URLs, failing if one is missing")
+	rateLimit       = commandLine.Duration("rate-limit", time.Second, "minimum interval between requests for inputs given as URLs")
+	watch           = commandLine.Bool("watch", false, "keep running, regenerating whenever the spec, overlay, header, reserved, override or template files change")
+	watchInterval   = commandLine.Duration("watch-interval", 500*time.Millisecond, "how often -watch checks the files for changes")
+
+	// overlays lists the -overlay files, applied in order over the element table.
+	overlays stringList
+
+	// basicAttrs lists the properties of BasicHTMLElement, which every element supports. They are declared by the
+	// hand-written code; only the options setting them are generated. Key is React's own prop, which keeps the
+	// identity of the items of a list across renders, and never reaches the DOM.
+	basicAttrs = []Attr{
+		{Name: "className", Override: "ClassName"},
+		{Name: "dangerouslySetInnerHTML", Override: "DangerouslySetInnerHTML", Type: "*DangerousInnerHTML"},
+		{Name: "id", Override: "ID"},
+		{Name: "key", Override: "Key"},
+		{Name: "onChange", Override: "OnChange", Type: "OnChange"},
+		{Name: "onClick", Override: "OnClick", Type: "OnClick"},
+		{Name: "role", Override: "Role"},
+		{Name: "style", Override: "Style", Type: "*CSS"},
+	}
+
+	// groups contains the attribute groups shared by several elements, keyed by group name. Each group is generated
+	// once as a struct embedded in the props of the elements that list it.
+	groups = catalog.Groups
 
 	// elements contains all of the Go wrappers to generate for the underlying HTML elements.
-	// Commented items have already been hand-written.
-	elements = map[string]Desc{
-		// "a"
-		"abbr":    Desc{},
-		"acronym": Desc{},
-		"address": Desc{},
-		"applet": Desc{
-			Attributes: []Attr{
-				{Name: "align"},
-				{Name: "alt"},
-				{Name: "archive"},
-				{Name: "code"},
-				{Name: "codebase"},
-				{Name: "datafld", Override: "DataFld"},
-				{Name: "datasrc", Override: "DataSrc"},
-				{Name: "height"},
-				{Name: "hspace", Override: "HSpace"},
-				{Name: "mayscript", Override: "MayScript"},
-				{Name: "name"},
-				{Name: "object"},
-				{Name: "src"},
-				{Name: "vspace", Override: "VSpace"},
-				{Name: "width"},
-			},
-		},
-		"area": Desc{
-			Attributes: []Attr{
-				{Name: "alt"},
-				{Name: "coords"},
-				{Name: "download"},
-				{Name: "href"},
-				{Name: "hreflang", Override: "HrefLang"},
-				{Name: "media"},
-				{Name: "referrerpolicy", Override: "ReferrerPolicy"},
-				{Name: "rel"},
-				{Name: "shape"},
-				{Name: "target"},
-			},
-		},
-		"article": Desc{},
-		"aside":   Desc{},
-		"audio": Desc{
-			Attributes: []Attr{
-				{Name: "autoplay", Override: "AutoPlay"},
-				{Name: "buffered"},
-				{Name: "controls"},
-				{Name: "loop"},
-				{Name: "mozCurrentSampleOffset", Override: "MozCurrentSampleOffset"},
-				{Name: "muted"},
-				{Name: "played"},
-				{Name: "preload"},
-				{Name: "src"},
-				{Name: "volume"},
-			},
-		},
-		"b": Desc{},
-		"base": Desc{
-			Attributes: []Attr{
-				{Name: "href"},
-				{Name: "target"},
-			},
-		},
-		"basefont": Desc{
-			Attributes: []Attr{
-				{Name: "color"},
-				{Name: "face"},
-				{Name: "size"},
-			},
-			Override: "BaseFont",
-		},
-		"bdi": Desc{},
-		"bdo": Desc{},
-		"blockquote": Desc{
-			Attributes: []Attr{
-				{Name: "cite"},
-			},
-			Override: "BlockQuote",
-		},
-		"body": Desc{
-			Attributes: []Attr{
-				{Name: "onafterprint", Override: "OnAfterPrint"},
-				{Name: "onbeforeprint", Override: "OnBeforePrint"},
-				{Name: "onbeforeunload", Override: "OnBeforeUnload"},
-				{Name: "onblur", Override: "OnBlur"},
-				{Name: "onerror", Override: "OnError"},
-				{Name: "onfocus", Override: "OnFocus"},
-				{Name: "onhashchange", Override: "OnHashChange"},
-				{Name: "onlanguagechange", Override: "OnLanguageChange"},
-				{Name: "onload", Override: "OnLoad"},
-				{Name: "onmessage", Override: "OnMessage"},
-				{Name: "onoffline", Override: "OnOffline"},
-				{Name: "ononline", Override: "OnOnline"},
-				{Name: "onpopstate", Override: "OnPopState"},
-				{Name: "onredo", Override: "OnRedo"},
-				{Name: "onresize", Override: "OnResize"},
-				{Name: "onstorage", Override: "OnStorage"},
-				{Name: "onundo", Override: "OnUndo"},
-				{Name: "onunload", Override: "OnUnload"},
-			},
-		},
-		// "br"
-		// "button"
-		"canvas": Desc{
-			Attributes: []Attr{
-				{Name: "height"},
-				{Name: "width"},
-			},
-		},
-		"caption": Desc{},
-		"cite":    Desc{},
-		// "code"
-		"col": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-				{Name: "span"},
-			},
-		},
-		"colgroup": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-				{Name: "span"},
-			},
-		},
-		"data": Desc{
-			Attributes: []Attr{
-				{Name: "value"},
-			},
-		},
-		"datalist": Desc{
-			Override: "DataList",
-		},
-		"dd": Desc{},
-		"del": Desc{
-			Attributes: []Attr{
-				{Name: "cite"},
-				{Name: "datetime", Override: "DateTime"},
-			},
-		},
-		"details": Desc{
-			Attributes: []Attr{
-				{Name: "open", Type: "bool"},
-			},
-		},
-		"dfn": Desc{},
-		"dialog": Desc{
-			Attributes: []Attr{
-				{Name: "open", Type: "bool"},
-			},
-		},
-		// "div"
-		"dl": Desc{},
-		"dt": Desc{},
-		"em": Desc{},
-		"embed": Desc{
-			Attributes: []Attr{
-				{Name: "height"},
-				{Name: "src"},
-				{Name: "type"},
-				{Name: "width"},
-			},
-		},
-		"fieldset": Desc{
-			Attributes: []Attr{
-				{Name: "disabled", Type: "bool"},
-				{Name: "form"},
-				{Name: "name"},
-			},
-			Override: "FieldSet",
-		},
-		"figcaption": Desc{
-			Override: "FigCaption",
-		},
-		"figure": Desc{},
-		// "footer"
-		// "form"
-		// "h1"
-		"h2": Desc{},
-		// "h3"
-		// "h4"
-		"h5":     Desc{},
-		"h6":     Desc{},
-		"head":   Desc{},
-		"header": Desc{},
-		"hgroup": Desc{
-			Override: "HGroup",
-		},
-		// "hr"
-		"html": Desc{
-			Attributes: []Attr{
-				{Name: "xmlns", Override: "XMLNS"},
-			},
-			Override: "HTML",
-		},
-		// "i"
-		// "iframe"
-		// "img"
-		// "input"
-		"ins": Desc{
-			Attributes: []Attr{
-				{Name: "cite"},
-				{Name: "datetime", Override: "DateTime"},
-			},
-		},
-		"kbd": Desc{},
-		// "label"
-		"legend": Desc{},
-		// "li"
-		"link": Desc{
-			Attributes: []Attr{
-				{Name: "as"},
-				{Name: "crossorigin", Override: "CrossOrigin"},
-				{Name: "disabled", Type: "bool"},
-				{Name: "href"},
-				{Name: "hreflang", Override: "HrefLang"},
-				{Name: "integrity"},
-				{Name: "media"},
-				{Name: "methods"},
-				{Name: "prefetch"},
-				{Name: "referrerpolicy", Override: "ReferrerPolicy"},
-				{Name: "rel"},
-				{Name: "sizes"},
-				{Name: "target"},
-				{Name: "title"},
-				{Name: "type"},
-			},
-		},
-		"main": Desc{},
-		"map": Desc{
-			Attributes: []Attr{
-				{Name: "name"},
-			},
-		},
-		"mark": Desc{},
-		"menu": Desc{
-			Attributes: []Attr{
-				{Name: "type"},
-			},
-		},
-		"meta": Desc{
-			Attributes: []Attr{
-				{Name: "charset", Override: "CharSet"},
-				{Name: "content"},
-				{Name: "http-equiv", Override: "HTTPEquiv"},
-				{Name: "name"},
-			},
-		},
-		"meter": Desc{
-			Attributes: []Attr{
-				{Name: "value", Type: "float64"},
-				{Name: "min", Type: "float64"},
-				{Name: "max", Type: "float64"},
-				{Name: "low", Type: "float64"},
-				{Name: "high", Type: "float64"},
-				{Name: "optimum", Type: "float64"},
-				{Name: "form"},
-			},
-		},
-		// "nav"
-		"noscript": Desc{
-			Override: "NoScript",
-		},
-		"object": Desc{
-			Attributes: []Attr{
-				{Name: "data"},
-				{Name: "form"},
-				{Name: "height"},
-				{Name: "name"},
-				{Name: "type"},
-				{Name: "typemustmatch", Override: "TypeMustMatch"},
-				{Name: "usemap", Override: "UseMap"},
-				{Name: "width"},
-			},
-		},
-		"ol": Desc{
-			Attributes: []Attr{
-				{Name: "compact"},
-				{Name: "reversed", Type: "bool"},
-				{Name: "start"},
-				{Name: "type"},
-			},
-		},
-		"optgroup": Desc{
-			Attributes: []Attr{
-				{Name: "disabled", Type: "bool"},
-				{Name: "label"},
-			},
-			Override: "OptGroup",
-		},
-		// "option"
-		"output": Desc{
-			Attributes: []Attr{
-				{Name: "for"},
-				{Name: "form"},
-				{Name: "name"},
-			},
-		},
-		// "p"
-		"param": Desc{
-			Attributes: []Attr{
-				{Name: "name"},
-				{Name: "value"},
-			},
-		},
-		"picture": Desc{},
-		// "pre"
-		"progress": Desc{
-			Attributes: []Attr{
-				{Name: "max", Type: "float64"},
-				{Name: "value", Type: "float64"},
-			},
-		},
-		"q": Desc{
-			Attributes: []Attr{
-				{Name: "cite"},
-			},
-		},
-		"rp": Desc{
-			Override: "RP",
-		},
-		"rt": Desc{
-			Override: "RT",
-		},
-		"rtc": Desc{
-			Override: "RTC",
-		},
-		"ruby": Desc{},
-		"s": Desc{
-			Override: "Strike", // The name is different from <s> because of an identifier name conflict.
-		},
-		"samp": Desc{},
-		"script": Desc{
-			Attributes: []Attr{
-				{Name: "async"},
-				{Name: "crossorigin", Override: "CrossOrigin"},
-				{Name: "defer"},
-				{Name: "integrity"},
-				{Name: "nomodule", Override: "NoModule"},
-				{Name: "nonce"},
-				{Name: "src"},
-				{Name: "text"},
-				{Name: "type"},
-			},
-		},
-		"section": Desc{},
-		// "select"
-		"slot": Desc{
-			Attributes: []Attr{
-				{Name: "name"},
-			},
-		},
-		"small": Desc{},
-		"source": Desc{
-			Attributes: []Attr{
-				{Name: "sizes"},
-				{Name: "src"},
-				{Name: "srcset", Override: "SrcSet"},
-				{Name: "type"},
-				{Name: "media"},
-			},
-		},
-		// "span"
-		"strong": Desc{},
-		"style": Desc{
-			Attributes: []Attr{
-				{Name: "type"},
-				{Name: "media"},
-				{Name: "nonce"},
-				{Name: "title"},
-			},
-		},
-		"sub": Desc{},
-		// "table"
-		"tbody": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-			},
-		},
-		"td": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-				{Name: "colspan", Override: "ColSpan"},
-				{Name: "headers"},
-				{Name: "rowspan", Override: "RowSpan"},
-			},
-		},
-		"template": Desc{},
-		"tfoot": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-			},
-		},
-		"th": Desc{
-			Attributes: []Attr{
-				{Name: "abbr"},
-				{Name: "bgcolor", Override: "BGColor"},
-				{Name: "colspan", Override: "ColSpan"},
-				{Name: "headers"},
-				{Name: "rowspan", Override: "RowSpan"},
-				{Name: "scope"},
-			},
-		},
-		"thead": Desc{
-			Attributes: []Attr{
-				{Name: "bgcolor", Override: "BGColor"},
-			},
-		},
-		"time": Desc{
-			Attributes: []Attr{
-				{Name: "datetime", Override: "DateTime"},
-			},
-		},
-		"title": Desc{},
-		"tr":    Desc{},
-		"track": Desc{
-			Attributes: []Attr{
-				{Name: "default", Type: "bool"},
-				{Name: "kind"},
-				{Name: "label"},
-				{Name: "src"},
-				{Name: "srclang", Override: "SrcLang"},
-			},
-		},
-		"u": Desc{},
-		// "ul"
-		"var": Desc{},
-		"video": Desc{
-			Attributes: []Attr{
-				{Name: "autoplay"},
-				{Name: "buffered"},
-				{Name: "controls"},
-				{Name: "crossorigin", Override: "CrossOrigin"},
-				{Name: "height"},
-				{Name: "loop"},
-				{Name: "muted"},
-				{Name: "played"},
-				{Name: "preload"},
-				{Name: "poster"},
-				{Name: "src"},
-				{Name: "width"},
-				{Name: "playsinline", Override: "PlaysInline"},
-			},
-		},
-		"wbr": Desc{},
-	}
+	// HandWritten items are maintained by hand upstream and are only generated with -all.
+	elements = catalog.Elements
 )
 
-func main() {
-	flag.CommandLine.Usage = usage
-	flag.Parse()
+// stringList is a flag.Value collecting every occurrence of a repeated flag.
+type stringList []string
 
-	primary := template.Must(template.New("primary").Parse(primaryTemplate))
-	test := template.Must(template.New("test").Parse(testTemplate))
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
 
-	for k, v := range elements {
-		var upper string
-		if v.Override != "" {
-			upper = v.Override
-		} else {
-			upper = strings.ToUpper(string(k[0])) + k[1:]
-		}
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func init() {
+	commandLine.Var(&overlays, "overlay", "JSON overlay `file` merged over the element table; may be repeated")
+	commandLine.Usage = usage
+}
+
+// Main runs the elemental command with the command-line arguments args, which exclude the program name, and returns
+// its exit status. The flags are kept in package variables, so Main must not be called concurrently.
+func Main(args []string) int {
+	// Flags before the command name are accepted for compatibility with the flat command line of earlier releases.
+	if err := commandLine.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return exitUsage
+	}
+	name, args := "generate", commandLine.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		return runHelp(args)
+	}
+
+	c, ok := lookupCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		usage()
+		return exitUsage
+	}
+	return c.run(c.flagSet(), args)
+}
 
-		var attrs []templAttr
-		for _, a := range v.Attributes {
-			js := a.Name
-			var name string
-			if a.Override == "" {
-				name = strings.ToUpper(string(js[0])) + js[1:]
-			} else {
-				name = a.Override
+// run generates the element wrappers as the flags direct and returns the exit status.
+func run() int {
+	start := time.Now()
+	if *slowest < 0 {
+		fmt.Fprintln(os.Stderr, "-slowest must not be negative")
+		return exitUsage
+	}
+	spec, table, attrGroups, err := loadTable()
+	if err != nil {
+		return reportError(err, PhaseSpec, exitSpec)
+	}
+
+	var reserved []string
+	if *reservedFile != "" {
+		b, err := os.ReadFile(*reservedFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		for _, l := range strings.Split(string(b), "\n") {
+			if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+				reserved = append(reserved, l)
 			}
-			var t string
-			if a.Type == "" {
-				t = "string"
-			} else {
-				t = a.Type
+		}
+	}
+
+	header := defaultHeader
+	if *headerFile != "" {
+		b, err := os.ReadFile(*headerFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		header = string(b)
+	}
+
+	opts := Options{
+		Dir:          *outputDirectory,
+		Elements:     table,
+		Groups:       attrGroups,
+		Package:      spec.Package,
+		ImportPath:   spec.ImportPath,
+		Naming:       spec.Naming,
+		Header:       header,
+		Reserved:     reserved,
+		HandWritten:  *all,
+		OverrideDir:  *overrideDir,
+		TemplateDir:  *templateDir,
+		Helpers:      spec.TemplateHelpers,
+		Prune:        *prune,
+		DryRun:       *dryRun || *check,
+		GoImports:    *goImports,
+		Workers:      *workers,
+		KeepGoing:    *keepGoing,
+		CompileCheck: *compileCheck,
+		Stubs:        *stubs,
+		Refs:         *refs,
+		TypedStyle:   *typedStyle,
+		Tests:        *tests,
+		A11yTests:    *a11yTests,
+		A11yLint:     *a11yLint,
+		Build:        spec.Build,
+		TestBuild:    spec.TestBuild,
+		Logger:       logger,
+	}
+	if *outputDirectory == "-" {
+		opts.Dir, opts.Output = "", os.Stdout
+	}
+	var archived *MemFS
+	if *archive != "" {
+		if opts.DryRun || opts.Output != nil {
+			fmt.Fprintln(os.Stderr, "-archive cannot be combined with -dry-run, -check or -o -")
+			return exitUsage
+		}
+		if _, err := archiveFormat(*archive); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		archived = &MemFS{}
+		opts.Dir, opts.FS, opts.Prune = "", archived, false
+	}
+
+	// Breaking changes are looked for in a dry run first, so that nothing is written if there are any.
+	if *failOnBreaking {
+		check := opts
+		check.DryRun, check.DetectBreaking = true, true
+		res, err := Generate(check)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		if len(res.Breaking) > 0 {
+			fmt.Fprintf(os.Stderr, "%d breaking change(s) to the generated API:\n", len(res.Breaking))
+			for _, c := range res.Breaking {
+				fmt.Fprintf(os.Stderr, "  %s\n", c)
 			}
-			attrs = append(attrs, templAttr{Name: name, JS: js, Type: t})
+			return exitBreaking
 		}
+	}
+
+	// The indicator is left out when the output is not watched by a user or would be interleaved with other lines.
+	finish := func() {}
+	if isTerminal(os.Stdout) && opts.Output == nil && !*quiet && !*verbose && *logFormat == "text" {
+		opts.Progress, finish = progressLine(os.Stderr)
+	}
+
+	res, err := Generate(opts)
+	finish()
+	if err != nil {
+		return reportError(err, "", exitUsage)
+	}
+
+	if *verbose {
+		reportTimings(res.Timings, *slowest)
+	}
 
-		e := templElem{
-			Elem:  upper + "Elem",
-			Name:  k,
-			Props: upper + "Props",
-			Upper: upper,
-			Attrs: attrs,
+	if *dryRun {
+		for _, d := range res.Diffs {
+			fmt.Print(d.Diff)
 		}
+	}
+	logger.Info("generated", "elements", len(res.Timings), "files", len(res.Written), "pruned", len(res.Pruned),
+		"failures", len(res.Failures), "elapsed", time.Since(start).Round(time.Millisecond))
 
-		executeTemplate(k+"_elem.go", primary, e)
-		executeTemplate(k+"_elem_test.go", test, e)
+	if len(res.Failures) > 0 {
+		return reportFailures(res.Failures)
 	}
-}
 
-func executeTemplate(n string, t *template.Template, e templElem) {
-	b := new(bytes.Buffer)
-	if err := t.Execute(b, e); err != nil {
-		panic(err)
+	if archived != nil {
+		if err := writeArchive(*archive, archived); err != nil {
+			return reportError(err, PhaseWrite, exitIO)
+		}
 	}
 
-	formatted, err := format.Source(b.Bytes())
+	if *check && len(res.Diffs) > 0 {
+		fmt.Fprintf(os.Stderr, "%d generated file(s) out of date:\n", len(res.Diffs))
+		for _, d := range res.Diffs {
+			fmt.Fprintf(os.Stderr, "  %s\n", d.Path)
+		}
+		return exitOutOfDate
+	}
+
+	return 0
+}
+
+// loadTable returns the spec given by -config, -package and -import-path together with the element table and the
+// attribute groups it describes, after applying the -overlay files.
+func loadTable() (*Spec, map[string]Desc, map[string][]Attr, error) {
+	spec := new(Spec)
+	if *config != "" {
+		var err error
+		if spec, err = loadSpec(*config, *configSum); err != nil {
+			return nil, nil, nil, err
+		}
+	} else if *configSum != "" {
+		return nil, nil, nil, errors.New("-config-sha256 requires -config")
+	}
+	attrGroups := spec.AttrGroups(groups)
+	table, err := spec.Table(elements, attrGroups)
 	if err != nil {
-		panic(err)
+		return nil, nil, nil, err
+	}
+	// The global groups apply again once the overlays are applied, to the elements they add too.
+	globalGroups := append([]string(nil), spec.GlobalGroups...)
+	if spec.Elements == nil {
+		globalGroups = append(globalGroups, catalog.GlobalGroups...)
+	}
+	for _, p := range overlays {
+		o, err := loadOverlay(p)
+		if err == nil {
+			table, err = o.Apply(table)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("overlay %s: %v", p, err)
+		}
+		for n, g := range o.Groups {
+			attrGroups[n] = g
+		}
+		globalGroups = append(globalGroups, o.GlobalGroups...)
+		spec.Naming.Initialisms = append(spec.Naming.Initialisms, o.Naming.Initialisms...)
+		spec.Naming.Words = append(spec.Naming.Words, o.Naming.Words...)
+	}
+	if len(overlays) > 0 {
+		for _, n := range globalGroups {
+			if _, ok := attrGroups[n]; !ok {
+				return nil, nil, nil, fmt.Errorf("unknown global attribute group %q", n)
+			}
+		}
+		table = withGlobalGroups(table, globalGroups, attrGroups)
+	}
+	if !*experimental {
+		table, attrGroups = stableOnly(table, attrGroups)
+	}
+	if *bcdFile != "" {
+		compat, err := loadBCD(*bcdFile)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		baseline, err := parseBrowsers(*minBrowsers)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var dropped []string
+		table, dropped = applyCompat(table, compat, baseline, len(baseline) > 0)
+		for _, d := range dropped {
+			logger.Warn(d, "flag", "min-browsers")
+		}
+	} else if *minBrowsers != "" {
+		return nil, nil, nil, errors.New("-min-browsers requires -bcd")
+	}
+	if *strict {
+		var dropped []string
+		table, attrGroups, dropped = standardOnly(table, attrGroups)
+		for _, d := range dropped {
+			logger.Warn(d, "flag", "strict")
+		}
+	}
+	if table, err = selectElements(table, *only, *skip); err != nil {
+		return nil, nil, nil, err
+	}
+	if *build != "" {
+		spec.Build = *build
+	}
+	if *testBuild != "" {
+		spec.TestBuild = *testBuild
+	}
+	if *packageName != "" {
+		spec.Package = *packageName
+	}
+	if *importPath != "" {
+		spec.ImportPath = *importPath
+	}
+
+	return spec, table, attrGroups, nil
+}
+
+// reportError prints err, which stopped generation at phase, and with -json-errors its JSON report, and returns code.
+func reportError(err error, phase Phase, code int) int {
+	fmt.Fprintln(os.Stderr, err)
+	if *jsonErrors {
+		printErrorReport([]Failure{{Phase: phase, Err: err}})
 	}
 
-	f, err := os.Create(filepath.Join(*outputDirectory, n))
+	return code
+}
+
+// printErrorReport prints failures to standard error as a single line of JSON, for tools that surface them in editors
+// and dashboards.
+func printErrorReport(failures []Failure) {
+	b, err := json.Marshal(struct {
+		Failures []Failure `json:"failures"`
+	}{failures})
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", b)
+}
+
+// reportFailures prints the elements that failed to generate followed by a summary of the files that could not be
+// written, grouped by cause, and with -json-errors their JSON report. It returns the exit code for the most severe
+// kind of failure.
+func reportFailures(failures []Failure) int {
+	if *jsonErrors {
+		defer printErrorReport(failures)
+	}
+
+	code := exitIO
+	byReason := make(map[string][]string)
+	for _, f := range failures {
+		switch f.Phase {
+		case PhaseWrite:
+			r := f.Reason()
+			byReason[r] = append(byReason[r], f.Path)
+			continue
+		case PhaseSpec:
+			code = exitSpec
+		default:
+			if code != exitSpec {
+				code = exitFormat
+			}
+		}
+		fmt.Fprintln(os.Stderr, f)
+	}
+	if len(byReason) == 0 {
+		return code
+	}
+
+	var reasons []string
+	for r := range byReason {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+
+	var n int
+	for _, paths := range byReason {
+		n += len(paths)
+	}
+	fmt.Fprintf(os.Stderr, "%d file(s) could not be written:\n", n)
+	for _, r := range reasons {
+		paths := byReason[r]
+		sort.Strings(paths)
+		fmt.Fprintf(os.Stderr, "  %s (%d):\n", r, len(paths))
+		for _, p := range paths {
+			fmt.Fprintf(os.Stderr, "    %s\n", p)
+		}
+	}
+
+	return code
+}
+
+// largeAttrs is the attribute count above which an element is flagged as a likely cause of slow generation.
+const largeAttrs = 20
+
+// reportTimings prints the n slowest elements and flags the ones that are pathologically slow or large, or rendered by
+// a custom template.
+func reportTimings(timings []ElementTiming, n int) {
+	if len(timings) == 0 {
+		return
+	}
+
+	sorted := append([]ElementTiming(nil), timings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Duration > sorted[j].Duration })
+
+	var total time.Duration
+	for _, t := range sorted {
+		total += t.Duration
 	}
+	median := sorted[len(sorted)/2].Duration
 
-	if _, err := f.Write(formatted); err != nil {
-		panic(err)
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	fmt.Fprintf(os.Stderr, "rendered %d element(s) in %s; slowest %d:\n", len(sorted), total, n)
+	for _, t := range sorted[:n] {
+		var notes []string
+		if median > 0 && t.Duration > 3*median {
+			notes = append(notes, fmt.Sprintf("%.1fx median", float64(t.Duration)/float64(median)))
+		}
+		if t.Attrs > largeAttrs {
+			notes = append(notes, fmt.Sprintf("%d attributes", t.Attrs))
+		}
+		if t.Custom {
+			notes = append(notes, "custom template")
+		}
+
+		fmt.Fprintf(os.Stderr, "  %-12s %10s", t.Tag, t.Duration)
+		if len(notes) > 0 {
+			fmt.Fprintf(os.Stderr, "  (%s)", strings.Join(notes, ", "))
+		}
+		fmt.Fprintln(os.Stderr)
 	}
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s\n", path.Base(os.Args[0]))
-	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "Usage: %[1]s [command] [flags] [arguments]\n\nCommands:\n", path.Base(os.Args[0]))
+	w := tabwriter.NewWriter(os.Stderr, 0, 8, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(os.Stderr, `
+Run "%s help command" for the flags of a command. Without a command, the flags are those of generate:
+
+`, path.Base(os.Args[0]))
+	commandLine.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+Exit status:
+  %d  -check found out-of-date files
+  %d  invalid command line or options
+  %d  invalid element description
+  %d  template, gofmt, or -compile-check failure
+  %d  file system failure
+  %d  convert found markup it cannot translate
+  %d  -fail-on-breaking found breaking changes to the generated API
+  %d  lint found hand-written elements that disagree with the spec
+`, exitOutOfDate, exitUsage, exitSpec, exitFormat, exitIO, exitConvert, exitBreaking, exitLint)
 }
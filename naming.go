@@ -0,0 +1,163 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"strings"
+	"unicode"
+)
+
+type (
+	// Namer turns HTML tag and attribute names into exported Go identifiers. Names are split into tokens at
+	// punctuation ("http-equiv") and lower-to-upper case changes ("mozCurrentSampleOffset"). A token that is an
+	// initialism is upper-cased; a token that can be split entirely into known words is capitalized word by word; any
+	// other token only has its first letter capitalized.
+	Namer struct {
+		initialisms map[string]bool
+		words       map[string]bool
+	}
+
+	// Naming extends the default initialisms and words of a Namer from a spec file, for example:
+	//
+	//	"naming": {
+	//		"initialisms": ["aria"],
+	//		"words": ["read", "only"]
+	//	}
+	Naming struct {
+		Initialisms []string `json:"initialisms,omitempty"`
+		Words       []string `json:"words,omitempty"`
+	}
+)
+
+var (
+	// defaultInitialisms are rendered in upper case wherever they appear as a word.
+	defaultInitialisms = []string{
+		"bg", "html", "http", "id", "rp", "rt", "rtc", "url", "xmlns",
+	}
+
+	// defaultWords are the known words that run-together names are split into. The list is deliberately
+	// conservative: a name is only split when every part is a known word, so adding a word can rename an existing
+	// identifier ("col" would turn Colgroup into ColGroup).
+	defaultWords = []string{
+		"action", "after", "allow", "auto", "base", "before", "block", "blur", "caption", "change", "char", "color",
+		"complete", "cross", "data", "date", "doc", "enc", "error", "fig", "field", "fld", "focus", "font", "form",
+		"full", "group", "h", "hash", "href", "inline", "is", "lang", "language", "length", "list", "load", "map",
+		"match", "max", "may", "message", "method", "min", "module", "must", "no", "offline", "on", "online", "only",
+		"opt", "origin", "play", "plays", "policy", "pop", "print", "quote", "read", "redo", "referrer", "resize", "row",
+		"screen", "script", "set", "space", "span", "src", "state", "storage", "target", "time", "type", "undo",
+		"unload", "use", "v", "validate",
+	}
+
+	// defaultNamer applies the default initialisms and words.
+	defaultNamer = NewNamer(Naming{})
+)
+
+// NewNamer returns a Namer that knows the default initialisms and words together with those in n.
+func NewNamer(n Naming) *Namer {
+	namer := &Namer{initialisms: make(map[string]bool), words: make(map[string]bool)}
+	for _, s := range append(defaultInitialisms, n.Initialisms...) {
+		namer.initialisms[strings.ToLower(s)] = true
+	}
+	for _, s := range append(defaultWords, n.Words...) {
+		namer.words[strings.ToLower(s)] = true
+	}
+
+	return namer
+}
+
+// Name returns the Go identifier for the HTML name s.
+func (n *Namer) Name(s string) string {
+	b := new(strings.Builder)
+	for _, t := range tokenize(s) {
+		for _, w := range n.split(t) {
+			if n.initialisms[w] {
+				b.WriteString(strings.ToUpper(w))
+			} else {
+				b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// split divides the lower-case token t into the fewest known words or initialisms that cover it exactly. A token that
+// cannot be covered is returned whole.
+func (n *Namer) split(t string) []string {
+	if n.initialisms[t] || n.words[t] {
+		return []string{t}
+	}
+
+	// best[i] holds the shortest covering of t[:i], or nil if there is none.
+	best := make([][]string, len(t)+1)
+	best[0] = []string{}
+	for i := 1; i <= len(t); i++ {
+		for j := 0; j < i; j++ {
+			w := t[j:i]
+			if best[j] == nil || !(n.words[w] || n.initialisms[w]) {
+				continue
+			}
+			if best[i] == nil || len(best[j])+1 < len(best[i]) {
+				best[i] = append(append([]string(nil), best[j]...), w)
+			}
+		}
+	}
+
+	if best[len(t)] == nil {
+		return []string{t}
+	}
+
+	return best[len(t)]
+}
+
+// tokenize splits s at non-alphanumeric characters and at lower-to-upper case changes, returning the tokens in lower
+// case.
+func tokenize(s string) []string {
+	var tokens []string
+	var cur []rune
+	prevLower := false
+	flush := func() {
+		if len(cur) > 0 {
+			tokens = append(tokens, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			prevLower = false
+			continue
+		case unicode.IsUpper(r) && prevLower:
+			flush()
+		}
+		cur = append(cur, r)
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,138 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// override holds the declarations merged into an element's generated file from its companion override file.
+	override struct {
+		// Path is the override file the declarations were read from.
+		Path string
+
+		// Imports are the import specs of the override file, such as `"fmt"` or `dom "honnef.co/go/js/dom"`.
+		Imports []string
+
+		// Fields are the extra fields declared on the element's internal props struct.
+		Fields []string
+
+		// Decls are the remaining top-level declarations, such as extra methods on the element type.
+		Decls []string
+	}
+)
+
+// loadOverride reads the override file for the element k from dir, if there is one. An override file is named after
+// the element's generated file with an _override.go suffix instead of _elem.go, for example a_override.go, and is a
+// Go source file in the target package. It should carry a "//go:build ignore" constraint when dir is itself part of a
+// Go package. A declaration of the internal props struct, such as
+//
+//	type _AProps struct {
+//		Download string `js:"download"`
+//	}
+//
+// contributes its fields to the generated struct; every other declaration is appended to the generated file
+// verbatim.
+func loadOverride(dir, k string, e templElem) (*override, error) {
+	p := filepath.Join(dir, fileBase(k)+"_override.go")
+	src, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if f.Name.Name != e.Package {
+		return nil, fmt.Errorf("%s: package %s, want %s", p, f.Name.Name, e.Package)
+	}
+
+	text := func(from, to token.Pos) string {
+		return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+	}
+	start := func(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+		if doc != nil {
+			return doc.Pos()
+		}
+		return pos
+	}
+
+	o := &override{Path: p}
+	for _, is := range f.Imports {
+		o.Imports = append(o.Imports, text(is.Pos(), is.End()))
+	}
+
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok {
+			if gd.Tok == token.IMPORT {
+				continue
+			}
+			if st := propsStruct(gd, "_"+e.Props); st != nil {
+				for _, fld := range st.Fields.List {
+					end := fld.End()
+					if fld.Comment != nil {
+						end = fld.Comment.End()
+					}
+					o.Fields = append(o.Fields, text(start(fld.Doc, fld.Pos()), end))
+				}
+				continue
+			}
+			o.Decls = append(o.Decls, text(start(gd.Doc, gd.Pos()), gd.End()))
+			continue
+		}
+
+		fd := d.(*ast.FuncDecl)
+		o.Decls = append(o.Decls, text(start(fd.Doc, fd.Pos()), fd.End()))
+	}
+
+	return o, nil
+}
+
+// propsStruct returns the struct type of gd if gd declares exactly the type named name.
+func propsStruct(gd *ast.GenDecl, name string) *ast.StructType {
+	if gd.Tok != token.TYPE || len(gd.Specs) != 1 {
+		return nil
+	}
+
+	ts := gd.Specs[0].(*ast.TypeSpec)
+	if ts.Name.Name != name {
+		return nil
+	}
+	st, _ := ts.Type.(*ast.StructType)
+
+	return st
+}
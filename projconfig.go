@@ -0,0 +1,203 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// projectConfigNames are the names of the project configuration file, looked for in the current directory and its
+// parents.
+var projectConfigNames = []string{".elemental.yaml", ".elemental.yml"}
+
+// pathFlags are the flags naming files or directories. Relative paths given to them in a project configuration file
+// are relative to the directory of that file.
+var pathFlags = map[string]bool{
+	"o": true, "config": true, "header": true, "reserved": true, "overrides": true, "templates": true, "overlay": true,
+	"bcd": true, "archive": true, "cache-dir": true,
+}
+
+// findProjectConfig returns the path of the project configuration file nearest to dir, or "" if there is none.
+func findProjectConfig(dir string) string {
+	for {
+		for _, n := range projectConfigNames {
+			p := filepath.Join(dir, n)
+			if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+				return p
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configEntry is a setting of a project configuration file: a flag name and its values, more than one for a list.
+type configEntry struct {
+	line   int
+	name   string
+	values []string
+}
+
+// parseProjectConfig parses data, a project configuration file in the subset of YAML made of a mapping of flag names
+// to scalars or to lists of scalars, given as [a, b] or as "- a" lines, for example:
+//
+//	# .elemental.yaml
+//	o: react
+//	package: react
+//	templates: tools/templates
+//	overlay:
+//	  - overlays/project.json
+//	strict: true
+func parseProjectConfig(data []byte) ([]configEntry, error) {
+	var entries []configEntry
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		l := strings.TrimRight(s.Text(), " \t\r")
+		trimmed := strings.TrimSpace(l)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if len(entries) == 0 || l == trimmed {
+				return nil, fmt.Errorf("line %d: list item outside of a list", n)
+			}
+			v, err := configScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			e := &entries[len(entries)-1]
+			e.values = append(e.values, v)
+			continue
+		}
+
+		if l != trimmed {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n)
+		}
+		i := strings.Index(l, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: want name: value", n)
+		}
+		e := configEntry{line: n, name: strings.TrimSpace(l[:i])}
+		v := strings.TrimSpace(l[i+1:])
+		switch {
+		case v == "" || strings.HasPrefix(v, "#"):
+		case strings.HasPrefix(v, "["):
+			if !strings.HasSuffix(v, "]") {
+				return nil, fmt.Errorf("line %d: unterminated list", n)
+			}
+			for _, item := range strings.Split(v[1:len(v)-1], ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				sv, err := configScalar(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", n, err)
+				}
+				e.values = append(e.values, sv)
+			}
+		default:
+			sv, err := configScalar(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			e.values = []string{sv}
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, s.Err()
+}
+
+// configScalar returns the value of the YAML scalar v: a double-quoted string with Go escapes, a single-quoted
+// string, or a plain value, which ends at a comment.
+func configScalar(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		end := strings.LastIndex(v, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", v)
+		}
+		return strconv.Unquote(v[:end+1])
+	case strings.HasPrefix(v, "'"):
+		end := strings.LastIndex(v, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", v)
+		}
+		return strings.ReplaceAll(v[1:end], "''", "'"), nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+
+	return v, nil
+}
+
+// applyProjectConfig sets the flags of fs from the project configuration file p, except those given on the command
+// line, before or after the command name, which take precedence. Settings of flags that fs does not have but other
+// commands do are ignored. Relative paths are resolved against the directory of p.
+func applyProjectConfig(fs *flag.FlagSet, p string) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	entries, err := parseProjectConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", p, err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	commandLine.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	dir := filepath.Dir(p)
+	for _, e := range entries {
+		if commandLine.Lookup(e.name) == nil || e.name == "project-config" {
+			return fmt.Errorf("%s:%d: unknown setting %q", p, e.line, e.name)
+		}
+		if fs.Lookup(e.name) == nil || explicit[e.name] {
+			continue
+		}
+		for _, v := range e.values {
+			if pathFlags[e.name] && v != "-" && v != "" && !isURL(v) && !filepath.IsAbs(v) {
+				v = filepath.Join(dir, v)
+			}
+			if err := fs.Set(e.name, v); err != nil {
+				return fmt.Errorf("%s:%d: %s: %v", p, e.line, e.name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Protected regions let users keep hand-written code, such as helper methods, inside a generated file. Everything
+// between a begin marker and the following end marker is carried over verbatim when the file is regenerated. A
+// region may be named by text after the begin marker; unnamed regions are matched by their order in the file.
+//
+//	// elemental:begin-custom helpers
+//	func (a *AElem) IsExternal() bool { ... }
+//	// elemental:end-custom
+const (
+	regionBegin = "// elemental:begin-custom"
+	regionEnd   = "// elemental:end-custom"
+)
+
+type (
+	// region is the preserved body of a protected region.
+	region struct {
+		key  string
+		body []string
+	}
+)
+
+// mergeRegions copies the bodies of the protected regions of old into the regions of the same name in generated.
+// Regions of old that generated does not declare are appended to the end of the result so that user code is never
+// dropped.
+func mergeRegions(old, generated []byte) ([]byte, error) {
+	if !bytes.Contains(old, []byte(regionBegin)) {
+		return generated, nil
+	}
+
+	oldRegions, err := parseRegions(splitLines(old))
+	if err != nil {
+		return nil, fmt.Errorf("existing file: %v", err)
+	}
+	preserved := make(map[string][]string)
+	for _, r := range oldRegions {
+		preserved[r.key] = r.body
+	}
+
+	lines := splitLines(generated)
+	if _, err := parseRegions(lines); err != nil {
+		return nil, fmt.Errorf("generated file: %v", err)
+	}
+
+	var out []string
+	unnamed := 0
+	for i := 0; i < len(lines); i++ {
+		out = append(out, lines[i])
+		name, ok := beginMarker(lines[i])
+		if !ok {
+			continue
+		}
+
+		key := regionKey(name, &unnamed)
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != regionEnd {
+			i++
+			if _, ok := preserved[key]; !ok {
+				out = append(out, lines[i])
+			}
+		}
+		out = append(out, preserved[key]...)
+		delete(preserved, key)
+	}
+
+	// Keep the regions the new file no longer declares, in their original order.
+	for _, r := range oldRegions {
+		body, ok := preserved[r.key]
+		if !ok {
+			continue
+		}
+		name := r.key
+		if strings.HasPrefix(name, "#") {
+			name = ""
+		}
+		out = append(out, "", strings.TrimSpace(regionBegin+" "+name))
+		out = append(out, body...)
+		out = append(out, regionEnd)
+	}
+
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+// parseRegions returns the protected regions of lines, reporting unbalanced or duplicate markers.
+func parseRegions(lines []string) ([]region, error) {
+	var regions []region
+	seen := make(map[string]bool)
+	unnamed := 0
+	for i := 0; i < len(lines); i++ {
+		name, ok := beginMarker(lines[i])
+		if !ok {
+			if strings.TrimSpace(lines[i]) == regionEnd {
+				return nil, fmt.Errorf("line %d: %s without %s", i+1, regionEnd, regionBegin)
+			}
+			continue
+		}
+
+		r := region{key: regionKey(name, &unnamed)}
+		if seen[r.key] {
+			return nil, fmt.Errorf("line %d: duplicate region %q", i+1, name)
+		}
+		seen[r.key] = true
+
+		start := i + 1
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != regionEnd; i++ {
+			if _, ok := beginMarker(lines[i]); ok {
+				return nil, fmt.Errorf("line %d: nested %s", i+1, regionBegin)
+			}
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("line %d: %s without %s", start, regionBegin, regionEnd)
+		}
+		r.body = append([]string(nil), lines[start:i]...)
+		regions = append(regions, r)
+	}
+
+	return regions, nil
+}
+
+// beginMarker reports whether l is a begin marker and returns the region name that follows it.
+func beginMarker(l string) (string, bool) {
+	l = strings.TrimSpace(l)
+	if l != regionBegin && !strings.HasPrefix(l, regionBegin+" ") {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(l, regionBegin)), true
+}
+
+// regionKey returns the key that matches a region across regenerations: its name, or its position among the unnamed
+// regions counted by unnamed.
+func regionKey(name string, unnamed *int) string {
+	if name != "" {
+		return name
+	}
+	*unnamed++
+
+	return fmt.Sprintf("#%d", *unnamed)
+}
@@ -0,0 +1,196 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// rolesFile is the name of the file declaring Role and the WAI-ARIA roles.
+const rolesFile = "roles.go"
+
+// roleCategory is a category of the WAI-ARIA roles, declared together in rolesFile. Doc completes the sentence "The
+// <Name> roles". The constants of the roles are named Role followed by the Name of their enumValue or, if it is empty,
+// by the default name of their Value.
+type roleCategory struct {
+	Name, Doc string
+	Roles     []enumValue
+}
+
+// roleCategories are the categories of the concrete WAI-ARIA roles, in the order of the WAI-ARIA specification.
+var roleCategories = []roleCategory{
+	{
+		Name: "widget",
+		Doc:  "are the interactive elements of a user interface, standalone or composed of others",
+		Roles: []enumValue{
+			{Value: "button"},
+			{Value: "checkbox"},
+			{Name: "GridCell", Value: "gridcell"},
+			{Value: "link"},
+			{Name: "MenuItem", Value: "menuitem"},
+			{Name: "MenuItemCheckbox", Value: "menuitemcheckbox"},
+			{Name: "MenuItemRadio", Value: "menuitemradio"},
+			{Value: "option"},
+			{Name: "ProgressBar", Value: "progressbar"},
+			{Value: "radio"},
+			{Name: "ScrollBar", Value: "scrollbar"},
+			{Name: "SearchBox", Value: "searchbox"},
+			{Value: "slider"},
+			{Name: "SpinButton", Value: "spinbutton"},
+			{Value: "switch"},
+			{Value: "tab"},
+			{Name: "TabPanel", Value: "tabpanel"},
+			{Name: "TextBox", Value: "textbox"},
+			{Name: "TreeItem", Value: "treeitem"},
+			{Name: "ComboBox", Value: "combobox"},
+			{Value: "grid"},
+			{Name: "ListBox", Value: "listbox"},
+			{Value: "menu"},
+			{Name: "MenuBar", Value: "menubar"},
+			{Name: "RadioGroup", Value: "radiogroup"},
+			{Name: "TabList", Value: "tablist"},
+			{Value: "tree"},
+			{Name: "TreeGrid", Value: "treegrid"},
+		},
+	},
+	{
+		Name: "document structure",
+		Doc:  "organize the content of a page, which is not usually interactive",
+		Roles: []enumValue{
+			{Value: "application"},
+			{Value: "article"},
+			{Value: "blockquote"},
+			{Value: "caption"},
+			{Value: "cell"},
+			{Value: "code"},
+			{Name: "ColumnHeader", Value: "columnheader"},
+			{Value: "definition"},
+			{Value: "deletion"},
+			{Value: "document"},
+			{Value: "emphasis"},
+			{Value: "feed"},
+			{Value: "figure"},
+			{Value: "generic"},
+			{Value: "group"},
+			{Value: "heading"},
+			{Value: "img"},
+			{Value: "insertion"},
+			{Value: "list"},
+			{Name: "ListItem", Value: "listitem"},
+			{Value: "math"},
+			{Value: "meter"},
+			{Value: "none"},
+			{Value: "note"},
+			{Value: "paragraph"},
+			{Value: "presentation"},
+			{Value: "row"},
+			{Name: "RowGroup", Value: "rowgroup"},
+			{Name: "RowHeader", Value: "rowheader"},
+			{Value: "separator"},
+			{Value: "strong"},
+			{Value: "subscript"},
+			{Value: "superscript"},
+			{Value: "table"},
+			{Value: "term"},
+			{Value: "time"},
+			{Value: "toolbar"},
+			{Value: "tooltip"},
+		},
+	},
+	{
+		Name: "landmark",
+		Doc:  "identify the regions of a page that assistive technologies let the user navigate to",
+		Roles: []enumValue{
+			{Value: "banner"},
+			{Value: "complementary"},
+			{Name: "ContentInfo", Value: "contentinfo"},
+			{Value: "form"},
+			{Value: "main"},
+			{Value: "navigation"},
+			{Value: "region"},
+			{Value: "search"},
+		},
+	},
+	{
+		Name: "live region",
+		Doc:  "mark the regions whose changes assistive technologies announce",
+		Roles: []enumValue{
+			{Value: "alert"},
+			{Value: "log"},
+			{Value: "marquee"},
+			{Value: "status"},
+			{Value: "timer"},
+		},
+	},
+	{
+		Name: "window",
+		Doc:  "are windows within the page",
+		Roles: []enumValue{
+			{Name: "AlertDialog", Value: "alertdialog"},
+			{Value: "dialog"},
+		},
+	},
+}
+
+// templRoles is the data of the template that declares Role.
+type templRoles struct {
+	templTarget
+
+	Categories []roleCategory
+}
+
+// newTemplRoles resolves the names of the constants of the roleCategories.
+func newTemplRoles(target templTarget) templRoles {
+	data := templRoles{templTarget: target}
+	for _, c := range roleCategories {
+		tc := roleCategory{Name: c.Name, Doc: c.Doc}
+		for _, r := range c.Roles {
+			if r.Name == "" {
+				r.Name = defaultNamer.Name(r.Value)
+			}
+			tc.Roles = append(tc.Roles, enumValue{Name: "Role" + r.Name, Value: r.Value})
+		}
+		data.Categories = append(data.Categories, tc)
+	}
+
+	return data
+}
+
+// roleDecls returns the identifiers declared in rolesFile.
+func roleDecls() []string {
+	ids := []string{"Role"}
+	for _, c := range newTemplRoles(templTarget{}).Categories {
+		for _, r := range c.Roles {
+			ids = append(ids, r.Name)
+		}
+	}
+
+	return ids
+}
+
+// generateRoles renders with base the declaration of Role into rolesFile, and writes or diffs it according to opts.
+func generateRoles(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "roles", rolesFile, newTemplRoles(target), opts, res, produced)
+}
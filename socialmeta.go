@@ -0,0 +1,91 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// socialMetaFile is the name of the file declaring the constructors of the <meta> elements of the Open Graph protocol
+// and of Twitter cards, which describe the document when it is shared.
+const socialMetaFile = "meta_social.go"
+
+// socialMetaTag is a <meta> element of socialMetaTags: the constructor Meta<Func> sets the attribute Attr, property or
+// name, to Key and content to its argument. Doc completes the sentence "Meta<Func> returns the <meta> element
+// giving".
+type socialMetaTag struct {
+	Func, Attr, Key, Doc string
+}
+
+// socialMetaTags are the <meta> elements that have constructors in socialMetaFile, besides MetaTwitterCard, which
+// takes a TwitterCard.
+var socialMetaTags = []socialMetaTag{
+	{Func: "OGTitle", Attr: "property", Key: "og:title", Doc: "the title of the document"},
+	{Func: "OGDescription", Attr: "property", Key: "og:description", Doc: "a one- or two-sentence description"},
+	{Func: "OGType", Attr: "property", Key: "og:type", Doc: "the type of the object, such as website or article"},
+	{Func: "OGURL", Attr: "property", Key: "og:url", Doc: "the canonical URL of the object"},
+	{Func: "OGImage", Attr: "property", Key: "og:image", Doc: "the URL of the image representing the object"},
+	{Func: "OGImageAlt", Attr: "property", Key: "og:image:alt", Doc: "the text alternative of the og:image"},
+	{Func: "OGSiteName", Attr: "property", Key: "og:site_name", Doc: "the name of the site the object belongs to"},
+	{Func: "OGLocale", Attr: "property", Key: "og:locale", Doc: "the locale of the object, such as en_US"},
+	{Func: "TwitterSite", Attr: "name", Key: "twitter:site", Doc: "the @username of the site"},
+	{Func: "TwitterCreator", Attr: "name", Key: "twitter:creator", Doc: "the @username of the author"},
+	{Func: "TwitterTitle", Attr: "name", Key: "twitter:title", Doc: "the title of the card"},
+	{Func: "TwitterDescription", Attr: "name", Key: "twitter:description", Doc: "the description of the card"},
+	{Func: "TwitterImage", Attr: "name", Key: "twitter:image", Doc: "the URL of the image of the card"},
+	{Func: "TwitterImageAlt", Attr: "name", Key: "twitter:image:alt", Doc: "the text alternative of the twitter:image"},
+}
+
+// templSocialMeta is the data of the template that declares the constructors of socialMetaTags.
+type templSocialMeta struct {
+	templTarget
+
+	Meta templElem
+	Tags []socialMetaTag
+}
+
+// socialMetaDecls returns the identifiers declared in socialMetaFile.
+func socialMetaDecls() []string {
+	ids := []string{"MetaProperty", "MetaTwitterCard", "TwitterCard", "TwitterCardSummary",
+		"TwitterCardSummaryLargeImage", "TwitterCardApp", "TwitterCardPlayer"}
+	for _, t := range socialMetaTags {
+		ids = append(ids, "Meta"+t.Func)
+	}
+
+	return ids
+}
+
+// generateSocialMeta renders with base the constructors of socialMetaTags into socialMetaFile, and writes or diffs it
+// according to opts, provided that the helpers of <meta> are generated and it has a string property.
+func generateSocialMeta(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	e, ok := planned["meta"]
+	if !ok || !e.hasHelperAttrs(elemHelpers["meta"]) ||
+		!e.hasHelperAttrs(elemHelper{Attrs: map[string]string{"property": "string"}}) {
+		return
+	}
+
+	data := templSocialMeta{templTarget: target, Meta: e, Tags: socialMetaTags}
+	generateShared(base, "socialmeta", socialMetaFile, data, opts, res, produced)
+}
@@ -0,0 +1,530 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// Spec is the element table read from a spec file, together with the package it is generated into and the
+	// naming dictionary (see Naming) extending the defaults, for example:
+	//
+	//	{
+	//		"package": "react",
+	//		"importPath": "myitcv.io/react",
+	//		"elements": {
+	//			"abbr": {},
+	//			"blockquote": {
+	//				"attributes": [{"name": "cite"}]
+	//			},
+	//			"s": {"override": "Strike"}
+	//		},
+	//		"customElements": {
+	//			"my-widget": {
+	//				"groups": ["labelled"],
+	//				"attributes": [{"name": "open", "type": "bool"}]
+	//			}
+	//		},
+	//		"groups": {
+	//			"labelled": [{"name": "label"}]
+	//		}
+	//	}
+	//
+	// When elements is omitted the built-in table is used. Groups are added to the built-in attribute groups, replacing
+	// those of the same name. GlobalGroups names the attribute groups that every element, including the custom
+	// elements, has without listing them, like the global attributes of HTML. Custom elements (Web Components) are
+	// generated alongside the elements exactly like built-ins; their names must be valid custom element names and their
+	// attributes are limited to string and bool. Version identifies the revision of an element catalog, such as the
+	// embedded one; it does not affect the generated code.
+	Spec struct {
+		Version        string            `json:"version,omitempty"`
+		Package        string            `json:"package,omitempty"`
+		ImportPath     string            `json:"importPath,omitempty"`
+		Naming         Naming            `json:"naming"`
+		Elements       map[string]Desc   `json:"elements,omitempty"`
+		CustomElements map[string]Desc   `json:"customElements,omitempty"`
+		Groups         map[string][]Attr `json:"groups,omitempty"`
+		GlobalGroups   []string          `json:"globalGroups,omitempty"`
+
+		// TemplateHelpers are named templates, keyed by name, made available to the templates; see Options.Helpers.
+		TemplateHelpers map[string]string `json:"templateHelpers,omitempty"`
+
+		// Build and TestBuild are the build constraints of the generated element files and tests; see
+		// Options.Build.
+		Build     string `json:"build,omitempty"`
+		TestBuild string `json:"testBuild,omitempty"`
+	}
+)
+
+type (
+	// Overlay is a project-local spec fragment layered over the element table at load time, for example:
+	//
+	//	{
+	//		"elements": {
+	//			"a": {"attributes": [{"name": "ping"}]},
+	//			"meter": {"attributes": [{"name": "value", "type": "int"}]},
+	//			"search": {}
+	//		},
+	//		"remove": ["acronym"],
+	//		"removeAttributes": {"applet": ["datafld", "datasrc"]}
+	//	}
+	//
+	// An element that is not yet in the table is added. For an existing element a non-empty override replaces the
+	// current one, experimental marks it experimental, the groups it does not use yet and the former names it does not
+	// list yet are appended, and each attribute replaces the non-empty fields of the attribute of the same name or is
+	// appended if there is none. Removals are applied before additions. Custom elements, groups, and naming entries are
+	// added as in a Spec, and so are global groups, which also apply to the elements added by other overlays. Listing
+	// an optional group of the catalog, such as rdfa, among the global groups of an overlay adds it to every element.
+	Overlay struct {
+		Naming           Naming              `json:"naming"`
+		Elements         map[string]Desc     `json:"elements,omitempty"`
+		CustomElements   map[string]Desc     `json:"customElements,omitempty"`
+		Groups           map[string][]Attr   `json:"groups,omitempty"`
+		GlobalGroups     []string            `json:"globalGroups,omitempty"`
+		Remove           []string            `json:"remove,omitempty"`
+		RemoveAttributes map[string][]string `json:"removeAttributes,omitempty"`
+	}
+)
+
+// reservedCustomNames are hyphenated names defined by SVG and MathML that cannot be used for custom elements.
+var reservedCustomNames = map[string]bool{
+	"annotation-xml":   true,
+	"color-profile":    true,
+	"font-face":        true,
+	"font-face-format": true,
+	"font-face-name":   true,
+	"font-face-src":    true,
+	"font-face-uri":    true,
+	"missing-glyph":    true,
+}
+
+// customElementName matches the valid custom element names that this tool accepts: a lower-case ASCII letter
+// followed by lower-case letters, digits, dots, underscores, and at least one hyphen.
+var customElementName = regexp.MustCompile(`^[a-z][a-z0-9._]*-[a-z0-9._-]*$`)
+
+// Table returns the element table to generate: the spec's elements, or builtin when it defines none, together with
+// its custom elements, all of them with the spec's GlobalGroups, which are among groups.
+func (s *Spec) Table(builtin map[string]Desc, groups map[string][]Attr) (map[string]Desc, error) {
+	elements := s.Elements
+	if elements == nil {
+		elements = builtin
+	}
+	if len(s.CustomElements) == 0 {
+		return withGlobalGroups(elements, s.GlobalGroups, groups), nil
+	}
+
+	table := make(map[string]Desc, len(elements)+len(s.CustomElements))
+	for k, d := range elements {
+		table[k] = d
+	}
+	for k, d := range s.CustomElements {
+		if err := validateCustomElem(k, d); err != nil {
+			return nil, fmt.Errorf("custom element <%s>: %v", k, err)
+		}
+		if _, ok := table[k]; ok {
+			return nil, fmt.Errorf("custom element <%s> is also defined as an element", k)
+		}
+		table[k] = d
+	}
+
+	return withGlobalGroups(table, s.GlobalGroups, groups), nil
+}
+
+// withGlobalGroups returns table with the attribute groups names, among groups, added to every element that does not
+// list them already, or table itself if names is empty. The attributes of the global groups take the place of the
+// attributes of the same name that the elements declare themselves, such as the property of <meta>.
+func withGlobalGroups(table map[string]Desc, names []string, groups map[string][]Attr) map[string]Desc {
+	if len(names) == 0 {
+		return table
+	}
+
+	global := make(map[string]bool)
+	for _, n := range names {
+		for _, a := range groups[n] {
+			global[a.Name] = true
+		}
+	}
+	with := make(map[string]Desc, len(table))
+	for k, d := range table {
+		listed := make(map[string]bool)
+		for _, n := range d.Groups {
+			listed[n] = true
+		}
+		gs := append([]string(nil), d.Groups...)
+		for _, n := range names {
+			if !listed[n] {
+				gs = append(gs, n)
+			}
+		}
+		d.Groups = gs
+
+		var attrs []Attr
+		for _, a := range d.Attributes {
+			if !global[a.Name] {
+				attrs = append(attrs, a)
+			}
+		}
+		d.Attributes = attrs
+		with[k] = d
+	}
+
+	return with
+}
+
+// loadOverlay reads the overlay file p.
+func loadOverlay(p string) (*Overlay, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+
+	o := new(Overlay)
+	if err := dec.Decode(o); err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+
+	return o, nil
+}
+
+// Apply returns a copy of table with the overlay merged into it. table itself is not modified.
+func (o *Overlay) Apply(table map[string]Desc) (map[string]Desc, error) {
+	merged := make(map[string]Desc, len(table))
+	for k, d := range table {
+		d.Attributes = append([]Attr(nil), d.Attributes...)
+		d.Groups = append([]string(nil), d.Groups...)
+		d.Formerly = append([]string(nil), d.Formerly...)
+		merged[k] = d
+	}
+
+	for _, k := range o.Remove {
+		if _, ok := merged[k]; !ok {
+			return nil, fmt.Errorf("cannot remove <%s>: no such element", k)
+		}
+		delete(merged, k)
+	}
+
+	for k, names := range o.RemoveAttributes {
+		d, ok := merged[k]
+		if !ok {
+			return nil, fmt.Errorf("cannot remove attributes of <%s>: no such element", k)
+		}
+		for _, n := range names {
+			i := attrIndex(d.Attributes, n)
+			if i < 0 {
+				return nil, fmt.Errorf("cannot remove attribute %q of <%s>: no such attribute", n, k)
+			}
+			d.Attributes = append(d.Attributes[:i], d.Attributes[i+1:]...)
+		}
+		merged[k] = d
+	}
+
+	for k, od := range o.Elements {
+		d, ok := merged[k]
+		if !ok {
+			merged[k] = od
+			continue
+		}
+
+		if od.Override != "" {
+			d.Override = od.Override
+		}
+		d.Groups = appendMissing(d.Groups, od.Groups...)
+		d.Formerly = appendMissing(d.Formerly, od.Formerly...)
+		if od.Experimental {
+			d.Experimental = true
+		}
+		for _, oa := range od.Attributes {
+			i := attrIndex(d.Attributes, oa.Name)
+			if i < 0 {
+				d.Attributes = append(d.Attributes, oa)
+				continue
+			}
+			if oa.Override != "" {
+				d.Attributes[i].Override = oa.Override
+			}
+			if oa.Type != "" {
+				d.Attributes[i].Type = oa.Type
+			}
+			if oa.NonStandard {
+				d.Attributes[i].NonStandard = true
+			}
+			if oa.Experimental {
+				d.Attributes[i].Experimental = true
+			}
+			if oa.Compat != "" {
+				d.Attributes[i].Compat = oa.Compat
+			}
+		}
+		merged[k] = d
+	}
+
+	for k, d := range o.CustomElements {
+		if err := validateCustomElem(k, d); err != nil {
+			return nil, fmt.Errorf("custom element <%s>: %v", k, err)
+		}
+		if _, ok := merged[k]; ok {
+			return nil, fmt.Errorf("custom element <%s> is also defined as an element", k)
+		}
+		merged[k] = d
+	}
+
+	return merged, nil
+}
+
+// attrIndex returns the index of the attribute named n in attrs, or -1.
+func attrIndex(attrs []Attr, n string) int {
+	for i, a := range attrs {
+		if a.Name == n {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// appendMissing appends to s the elements of add that it does not already contain.
+func appendMissing(s []string, add ...string) []string {
+next:
+	for _, a := range add {
+		for _, x := range s {
+			if x == a {
+				continue next
+			}
+		}
+		s = append(s, a)
+	}
+
+	return s
+}
+
+// standardOnly returns table and groups without the attributes marked NonStandard, and a description of what was
+// dropped from each element and group, in name order.
+func standardOnly(table map[string]Desc, groups map[string][]Attr) (map[string]Desc, map[string][]Attr, []string) {
+	return dropAttrs(table, groups, func(a Attr) bool { return a.NonStandard }, "non-standard")
+}
+
+// stableOnly returns table and groups without the elements and attributes marked Experimental.
+func stableOnly(table map[string]Desc, groups map[string][]Attr) (map[string]Desc, map[string][]Attr) {
+	stable := make(map[string]Desc, len(table))
+	for k, d := range table {
+		if !d.Experimental {
+			stable[k] = d
+		}
+	}
+	stable, groups, _ = dropAttrs(stable, groups, func(a Attr) bool { return a.Experimental }, "experimental")
+
+	return stable, groups
+}
+
+// selectElements returns table restricted to the elements listed in only, if it is not empty, less those listed in
+// skip. Both are comma-separated lists of tags, all of which must be in table.
+func selectElements(table map[string]Desc, only, skip string) (map[string]Desc, error) {
+	split := func(list string) ([]string, error) {
+		var tags []string
+		for _, k := range strings.Split(list, ",") {
+			if k = strings.TrimSpace(k); k == "" {
+				continue
+			}
+			if _, ok := table[k]; !ok {
+				return nil, fmt.Errorf("unknown element %q", k)
+			}
+			tags = append(tags, k)
+		}
+		return tags, nil
+	}
+
+	selected := table
+	if only != "" {
+		tags, err := split(only)
+		if err != nil {
+			return nil, fmt.Errorf("-only: %v", err)
+		}
+		selected = make(map[string]Desc, len(tags))
+		for _, k := range tags {
+			selected[k] = table[k]
+		}
+	}
+
+	tags, err := split(skip)
+	if err != nil {
+		return nil, fmt.Errorf("-skip: %v", err)
+	}
+	if len(tags) > 0 {
+		kept := make(map[string]Desc, len(selected))
+		for k, d := range selected {
+			kept[k] = d
+		}
+		for _, k := range tags {
+			delete(kept, k)
+		}
+		selected = kept
+	}
+
+	return selected, nil
+}
+
+// dropAttrs returns table and groups without the attributes for which drop reports true, and a description of what
+// was dropped from each element and group, in name order, calling the attributes kind.
+func dropAttrs(table map[string]Desc, groups map[string][]Attr, drop func(Attr) bool, kind string) (map[string]Desc, map[string][]Attr, []string) {
+	var dropped []string
+	filter := func(attrs []Attr, what string) []Attr {
+		var kept []Attr
+		var names []string
+		for _, a := range attrs {
+			if drop(a) {
+				names = append(names, a.Name)
+			} else {
+				kept = append(kept, a)
+			}
+		}
+		if len(names) > 0 {
+			dropped = append(dropped, fmt.Sprintf("%s: dropped %s attributes %s", what, kind, strings.Join(names, ", ")))
+		}
+		return kept
+	}
+
+	var tags, names []string
+	for k := range table {
+		tags = append(tags, k)
+	}
+	for n := range groups {
+		names = append(names, n)
+	}
+	sort.Strings(tags)
+	sort.Strings(names)
+
+	kept := make(map[string]Desc, len(table))
+	for _, k := range tags {
+		d := table[k]
+		d.Attributes = filter(d.Attributes, "<"+k+">")
+		kept[k] = d
+	}
+	keptGroups := make(map[string][]Attr, len(groups))
+	for _, n := range names {
+		keptGroups[n] = filter(groups[n], "group "+n)
+	}
+
+	return kept, keptGroups, dropped
+}
+
+// AttrGroups returns the attribute groups available to the spec's elements: builtin with the spec's groups added.
+func (s *Spec) AttrGroups(builtin map[string][]Attr) map[string][]Attr {
+	groups := make(map[string][]Attr, len(builtin)+len(s.Groups))
+	for n, g := range builtin {
+		groups[n] = g
+	}
+	for n, g := range s.Groups {
+		groups[n] = g
+	}
+
+	return groups
+}
+
+// validateCustomElem checks the name and attribute types of the custom element k described by d.
+func validateCustomElem(k string, d Desc) error {
+	if !customElementName.MatchString(k) || reservedCustomNames[k] {
+		return fmt.Errorf("not a valid custom element name")
+	}
+
+	for _, a := range d.Attributes {
+		switch a.Type {
+		case "", "string", "bool":
+		default:
+			return fmt.Errorf("attribute %q has type %s; custom element attributes must be string or bool", a.Name, a.Type)
+		}
+	}
+
+	return nil
+}
+
+// loadSpec reads the spec file p, the resource it names if it is a URL, or standard input if p is "-". Unless sum is
+// empty, the contents must have the hex-encoded SHA-256 digest sum.
+func loadSpec(p, sum string) (*Spec, error) {
+	var b []byte
+	var err error
+	if p == "-" {
+		b, err = io.ReadAll(os.Stdin)
+		p = "<stdin>"
+	} else {
+		b, err = readInput(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sum != "" {
+		got := sha256.Sum256(b)
+		if h := hex.EncodeToString(got[:]); !strings.EqualFold(h, sum) {
+			return nil, fmt.Errorf("%s: SHA-256 digest %s does not match the pinned %s", p, h, sum)
+		}
+	}
+
+	spec, err := decodeSpec(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+
+	return spec, nil
+}
+
+// decodeSpec parses a JSON spec, or a YAML one, see yamlToJSON, if it does not start with '{'. Unknown fields are
+// rejected so that misspelled keys do not silently drop settings.
+func decodeSpec(r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] != '{' {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	spec := new(Spec)
+	if err := dec.Decode(spec); err != nil {
+		return nil, err
+	}
+	if len(spec.Elements) == 0 && len(spec.CustomElements) == 0 {
+		return nil, fmt.Errorf("no elements or custom elements defined")
+	}
+
+	return spec, nil
+}
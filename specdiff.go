@@ -0,0 +1,356 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+type (
+	// specDiff lists the differences between the element tables of two specs.
+	specDiff struct {
+		// From and To are the versions of the compared specs, if they have any.
+		From string `json:"from,omitempty"`
+		To   string `json:"to,omitempty"`
+
+		Added   []string      `json:"added,omitempty"`
+		Removed []string      `json:"removed,omitempty"`
+		Changed []elemChanges `json:"changed,omitempty"`
+	}
+
+	// elemChanges lists the differences between two versions of an element. The attributes include those of the
+	// element's attribute groups.
+	elemChanges struct {
+		Element           string       `json:"element"`
+		Override          *valueChange `json:"override,omitempty"`
+		AddedAttributes   []Attr       `json:"addedAttributes,omitempty"`
+		RemovedAttributes []Attr       `json:"removedAttributes,omitempty"`
+		TypeChanges       []typeChange `json:"typeChanges,omitempty"`
+	}
+
+	// valueChange is a setting that changed from Old to New.
+	valueChange struct {
+		Old string `json:"old"`
+		New string `json:"new"`
+	}
+
+	// typeChange is an attribute whose Go type changed.
+	typeChange struct {
+		Attribute string `json:"attribute"`
+		valueChange
+	}
+)
+
+// diffSpecs compares the elements of the table from, whose attribute groups are fromGroups, with those of to.
+func diffSpecs(from, to map[string]Desc, fromGroups, toGroups map[string][]Attr) *specDiff {
+	d := new(specDiff)
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			d.Added = append(d.Added, k)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+
+	var tags []string
+	for k := range to {
+		if _, ok := from[k]; ok {
+			tags = append(tags, k)
+		}
+	}
+	sort.Strings(tags)
+	for _, k := range tags {
+		c := elemChanges{Element: k}
+		if o, n := from[k].Override, to[k].Override; o != n {
+			c.Override = &valueChange{o, n}
+		}
+
+		oldAttrs, newAttrs := effectiveAttrs(from[k], fromGroups), effectiveAttrs(to[k], toGroups)
+		oldByName := make(map[string]Attr)
+		for _, a := range oldAttrs {
+			oldByName[a.Name] = a
+		}
+		newByName := make(map[string]Attr)
+		for _, a := range newAttrs {
+			newByName[a.Name] = a
+			o, ok := oldByName[a.Name]
+			switch {
+			case !ok:
+				c.AddedAttributes = append(c.AddedAttributes, a)
+			case attrType(o) != attrType(a):
+				c.TypeChanges = append(c.TypeChanges, typeChange{a.Name, valueChange{attrType(o), attrType(a)}})
+			}
+		}
+		for _, a := range oldAttrs {
+			if _, ok := newByName[a.Name]; !ok {
+				c.RemovedAttributes = append(c.RemovedAttributes, a)
+			}
+		}
+
+		if c.Override != nil || len(c.AddedAttributes) > 0 || len(c.RemovedAttributes) > 0 || len(c.TypeChanges) > 0 {
+			d.Changed = append(d.Changed, c)
+		}
+	}
+
+	return d
+}
+
+// effectiveAttrs returns the attributes of the element d, those of its attribute groups first.
+func effectiveAttrs(d Desc, groups map[string][]Attr) []Attr {
+	var attrs []Attr
+	for _, g := range d.Groups {
+		attrs = append(attrs, groups[g]...)
+	}
+	return append(attrs, d.Attributes...)
+}
+
+// attrType returns the Go type of the attribute a.
+func attrType(a Attr) string {
+	if a.Type == "" {
+		return "string"
+	}
+	return a.Type
+}
+
+// print writes d in a human-readable form, one difference per line.
+func (d *specDiff) print() {
+	for _, k := range d.Added {
+		fmt.Printf("+ <%s>\n", k)
+	}
+	for _, k := range d.Removed {
+		fmt.Printf("- <%s>\n", k)
+	}
+	for _, c := range d.Changed {
+		if c.Override != nil {
+			fmt.Printf("~ <%s>: override %q -> %q\n", c.Element, c.Override.Old, c.Override.New)
+		}
+		for _, a := range c.AddedAttributes {
+			fmt.Printf("~ <%s>: + attribute %s (%s)\n", c.Element, a.Name, attrType(a))
+		}
+		for _, a := range c.RemovedAttributes {
+			fmt.Printf("~ <%s>: - attribute %s (%s)\n", c.Element, a.Name, attrType(a))
+		}
+		for _, t := range c.TypeChanges {
+			fmt.Printf("~ <%s>: attribute %s type %s -> %s\n", c.Element, t.Attribute, t.Old, t.New)
+		}
+	}
+}
+
+// empty reports whether d lists no difference.
+func (d *specDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// changelog writes d as a Markdown section of a changelog, headed by the version compared.
+func (d *specDiff) changelog(w io.Writer) {
+	fmt.Fprintf(w, "## Version %s\n\n", d.To)
+	if d.From != "" {
+		fmt.Fprintf(w, "Changes since version %s.\n\n", d.From)
+	}
+	if d.empty() {
+		fmt.Fprint(w, "No changes.\n\n")
+		return
+	}
+
+	list := func(title string, tags []string) {
+		if len(tags) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "### %s\n\n", title)
+		for _, k := range tags {
+			fmt.Fprintf(w, "- `<%s>`\n", k)
+		}
+		fmt.Fprintln(w)
+	}
+	list("Added elements", d.Added)
+	list("Removed elements", d.Removed)
+
+	if len(d.Changed) == 0 {
+		return
+	}
+	fmt.Fprint(w, "### Changed elements\n\n")
+	for _, c := range d.Changed {
+		var notes []string
+		if c.Override != nil {
+			notes = append(notes, fmt.Sprintf("renamed from %q to %q", c.Override.Old, c.Override.New))
+		}
+		if len(c.AddedAttributes) > 0 {
+			notes = append(notes, "added "+attrList(c.AddedAttributes))
+		}
+		if len(c.RemovedAttributes) > 0 {
+			notes = append(notes, "removed "+attrList(c.RemovedAttributes))
+		}
+		for _, t := range c.TypeChanges {
+			notes = append(notes, fmt.Sprintf("retyped `%s` from %s to %s", t.Attribute, t.Old, t.New))
+		}
+		fmt.Fprintf(w, "- `<%s>`: %s\n", c.Element, strings.Join(notes, "; "))
+	}
+	fmt.Fprintln(w)
+}
+
+// attrList returns the names and types of attrs, as in "`href` (string), `width` (int)".
+func attrList(attrs []Attr) string {
+	s := make([]string, len(attrs))
+	for i, a := range attrs {
+		s[i] = fmt.Sprintf("`%s` (%s)", a.Name, attrType(a))
+	}
+	return strings.Join(s, ", ")
+}
+
+// prependChangelog inserts section into the changelog file p, below its title if it starts with one, creating the
+// file if it does not exist.
+func prependChangelog(p string, section []byte) error {
+	old, err := os.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	title := []byte("# Changelog\n\n")
+	if bytes.HasPrefix(old, []byte("# ")) {
+		i := bytes.IndexByte(old, '\n') + 1
+		if i == 0 {
+			i = len(old)
+		}
+		title, old = old[:i], bytes.TrimLeft(old[i:], "\n")
+		title = append(append([]byte(nil), title...), '\n')
+	}
+
+	var buf bytes.Buffer
+	buf.Write(title)
+	buf.Write(section)
+	buf.Write(old)
+
+	return writeFile(p, buf.Bytes())
+}
+
+// loadDiffSide returns the version, element table and attribute groups of the spec file p, or of the embedded
+// catalog if p is empty.
+func loadDiffSide(p string) (string, map[string]Desc, map[string][]Attr, error) {
+	spec := catalog
+	if p != "" {
+		var err error
+		if spec, err = loadSpec(p, ""); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	attrGroups := spec.AttrGroups(groups)
+	table, err := spec.Table(elements, attrGroups)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%s: %v", p, err)
+	}
+
+	return spec.Version, table, attrGroups, nil
+}
+
+// writeChangelog adds the changelog section of d to the file p, or prints it if p is "-", and returns the exit
+// status. A spec that changed must carry a version different from the one it is compared with, so that the section
+// has a heading of its own.
+func writeChangelog(d *specDiff, p string) int {
+	if d.empty() {
+		fmt.Fprintln(os.Stderr, "no changes")
+		return 0
+	}
+	if d.To == "" || d.To == d.From {
+		fmt.Fprintf(os.Stderr, "the new spec changes the element table but its version is %q; give it a new \"version\"\n", d.To)
+		return exitSpec
+	}
+
+	var buf bytes.Buffer
+	d.changelog(&buf)
+	if p == "-" {
+		os.Stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := prependChangelog(p, buf.Bytes()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	return 0
+}
+
+// runDiff runs the diff command with the arguments args, comparing two spec files, or the embedded catalog with one
+// spec file. It returns the exit status.
+func runDiff(fs *flag.FlagSet, args []string) int {
+	asJSON := fs.Bool("json", false, "print the differences as JSON")
+	changelog := fs.String("changelog", "", "add the differences as a Markdown section to the changelog `file` (- prints it), requiring the new spec to carry a new version")
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+
+	var oldPath, newPath string
+	switch fs.NArg() {
+	case 1:
+		newPath = fs.Arg(0)
+	case 2:
+		oldPath, newPath = fs.Arg(0), fs.Arg(1)
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+
+	fromVersion, fromTable, fromGroups, err := loadDiffSide(oldPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+	toVersion, toTable, toGroups, err := loadDiffSide(newPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitSpec
+	}
+
+	d := diffSpecs(fromTable, toTable, fromGroups, toGroups)
+	d.From, d.To = fromVersion, toVersion
+	if *changelog != "" {
+		return writeChangelog(d, *changelog)
+	}
+	if !*asJSON {
+		d.print()
+		return 0
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(d); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitIO
+	}
+
+	return 0
+}
@@ -0,0 +1,411 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document that holds something: its number, its indentation and its text without the
+// indentation and any comment.
+type yamlLine struct {
+	n, indent int
+	text      string
+}
+
+// yamlParser parses the lines of a YAML document into the values that encoding/json would decode from the equivalent
+// JSON document.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// yamlToJSON converts data, a spec written in the subset of YAML that mirrors JSON, to JSON: block mappings and
+// sequences indented with spaces, flow sequences and mappings such as [a, b] and {name: x, type: bool}, and plain,
+// single-quoted or double-quoted scalars. true and false are booleans, null and ~ are null, and every other scalar is
+// a string, as a spec holds no numbers. Anchors, tags and multi-line scalars are not supported; write a multi-line
+// string, such as a template helper, double-quoted with \n escapes.
+func yamlToJSON(data []byte) ([]byte, error) {
+	p := new(yamlParser)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		l := strings.TrimRight(s.Text(), " \t\r")
+		t := strings.TrimLeft(l, " ")
+		if strings.HasPrefix(t, "\t") {
+			return nil, fmt.Errorf("line %d: indentation with a tab", n)
+		}
+		if t = yamlStripComment(t); t == "" || t == "---" {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{n, len(l) - len(strings.TrimLeft(l, " ")), t})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.lines) == 0 {
+		return []byte("{}"), nil
+	}
+
+	v, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].n)
+	}
+
+	return json.Marshal(v)
+}
+
+// block parses the mapping, sequence or scalar that starts at the current line, which is indented by indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	l := p.lines[p.pos]
+	if yamlItem(l.text) {
+		return p.sequence(indent)
+	}
+	if _, _, ok := yamlKey(l.text); ok {
+		return p.mapping(indent)
+	}
+
+	p.pos++
+	return yamlFlow(l)
+}
+
+// sequence parses the items of the block sequence indented by indent.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	seq := []interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !yamlItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.n)
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var v interface{}
+		var err error
+		if rest == "" {
+			p.pos++
+			v, err = p.nested(indent)
+		} else {
+			// An item that starts on the line of its dash is parsed as if that line were indented up to the item.
+			p.lines[p.pos] = yamlLine{l.n, l.indent + len(l.text) - len(rest), rest}
+			v, err = p.block(p.lines[p.pos].indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+	}
+
+	return seq, nil
+}
+
+// mapping parses the entries of the block mapping indented by indent.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := make(map[string]interface{})
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.n)
+		}
+		k, rest, ok := yamlKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key: value", l.n)
+		}
+		if _, dup := m[k]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.n, k)
+		}
+
+		p.pos++
+		var v interface{}
+		var err error
+		switch {
+		case rest != "":
+			v, err = yamlFlow(yamlLine{l.n, l.indent, rest})
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && yamlItem(p.lines[p.pos].text):
+			// A sequence may be indented as much as the key it is the value of.
+			v, err = p.sequence(indent)
+		default:
+			v, err = p.nested(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+// nested parses the block indented by more than indent that starts at the current line, or returns null if there is
+// none.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return p.block(p.lines[p.pos].indent)
+	}
+	return nil, nil
+}
+
+// yamlItem reports whether t, a line without its indentation, is an item of a block sequence.
+func yamlItem(t string) bool {
+	return t == "-" || strings.HasPrefix(t, "- ")
+}
+
+// yamlKey splits t, a line without its indentation, into the key of a mapping entry and the rest of the line, or
+// returns false if t is not a mapping entry.
+func yamlKey(t string) (string, string, bool) {
+	var k string
+	var i int
+	switch {
+	case t == "" || t[0] == '[' || t[0] == '{' || yamlItem(t):
+		return "", "", false
+	case t[0] == '"' || t[0] == '\'':
+		f := &yamlFlowParser{s: t}
+		v, err := f.quoted()
+		if err != nil {
+			return "", "", false
+		}
+		k, i = v, f.pos
+		if !strings.HasPrefix(t[i:], ":") {
+			return "", "", false
+		}
+	default:
+		if i = strings.Index(t, ": "); i < 0 {
+			if !strings.HasSuffix(t, ":") {
+				return "", "", false
+			}
+			i = len(t) - 1
+		}
+		k = strings.TrimSpace(t[:i])
+	}
+
+	rest := t[i+1:]
+	if rest != "" && rest[0] != ' ' {
+		return "", "", false
+	}
+	return k, strings.TrimSpace(rest), true
+}
+
+// yamlStripComment returns t without the comment that ends it, if any: a # at its start or after a space, outside
+// quotes.
+func yamlStripComment(t string) string {
+	var quote byte
+	for i := 0; i < len(t); i++ {
+		c := t[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || t[i-1] == ' '):
+			return strings.TrimRight(t[:i], " ")
+		}
+	}
+
+	return t
+}
+
+// yamlFlow parses the text of l, a scalar or a flow collection, which must take up all of it.
+func yamlFlow(l yamlLine) (interface{}, error) {
+	f := &yamlFlowParser{s: l.text}
+	v, err := f.value(false)
+	if err == nil {
+		f.space()
+		if f.pos < len(f.s) {
+			err = fmt.Errorf("unexpected %q", f.s[f.pos:])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", l.n, err)
+	}
+
+	return v, nil
+}
+
+// yamlFlowParser parses the scalars and flow collections in s from pos on.
+type yamlFlowParser struct {
+	s   string
+	pos int
+}
+
+// space skips the spaces at pos.
+func (f *yamlFlowParser) space() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+// value parses the scalar or flow collection at pos. Within a flow collection, as inFlow says, a plain scalar ends
+// at a comma or the end of the collection.
+func (f *yamlFlowParser) value(inFlow bool) (interface{}, error) {
+	f.space()
+	if f.pos == len(f.s) {
+		return nil, nil
+	}
+
+	switch f.s[f.pos] {
+	case '[':
+		f.pos++
+		seq := []interface{}{}
+		for {
+			f.space()
+			if f.pos < len(f.s) && f.s[f.pos] == ']' {
+				f.pos++
+				return seq, nil
+			}
+			v, err := f.value(true)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.pos++
+		m := make(map[string]interface{})
+		for {
+			f.space()
+			if f.pos < len(f.s) && f.s[f.pos] == '}' {
+				f.pos++
+				return m, nil
+			}
+			k, err := f.key()
+			if err != nil {
+				return nil, err
+			}
+			v, err := f.value(true)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		return f.quoted()
+	}
+
+	end := len(f.s)
+	if inFlow {
+		if i := strings.IndexAny(f.s[f.pos:], ",]}"); i >= 0 {
+			end = f.pos + i
+		}
+	}
+	v := strings.TrimSpace(f.s[f.pos:end])
+	f.pos = end
+	switch v {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+
+	return v, nil
+}
+
+// key parses the key of an entry of a flow mapping and the colon after it.
+func (f *yamlFlowParser) key() (string, error) {
+	var k string
+	if f.s[f.pos] == '"' || f.s[f.pos] == '\'' {
+		var err error
+		if k, err = f.quoted(); err != nil {
+			return "", err
+		}
+		f.space()
+	} else {
+		i := strings.IndexAny(f.s[f.pos:], ":,}")
+		if i < 0 {
+			return "", errors.New("unterminated flow mapping")
+		}
+		k = strings.TrimSpace(f.s[f.pos : f.pos+i])
+		f.pos += i
+	}
+	if f.pos == len(f.s) || f.s[f.pos] != ':' {
+		return "", fmt.Errorf("want : after key %q", k)
+	}
+	f.pos++
+
+	return k, nil
+}
+
+// separator skips the comma after an item of a flow collection, unless close, the end of the collection, follows.
+func (f *yamlFlowParser) separator(close byte) error {
+	f.space()
+	switch {
+	case f.pos == len(f.s):
+		return fmt.Errorf("unterminated flow collection, want %c", close)
+	case f.s[f.pos] == ',':
+		f.pos++
+	case f.s[f.pos] != close:
+		return fmt.Errorf("want , or %c, not %q", close, f.s[f.pos:])
+	}
+
+	return nil
+}
+
+// quoted parses the single- or double-quoted scalar at pos. Double-quoted scalars take Go escapes.
+func (f *yamlFlowParser) quoted() (string, error) {
+	q := f.s[f.pos]
+	for i := f.pos + 1; i < len(f.s); i++ {
+		switch {
+		case q == '"' && f.s[i] == '\\':
+			i++
+		case f.s[i] != q:
+		case q == '\'' && i+1 < len(f.s) && f.s[i+1] == '\'':
+			i++
+		case q == '"':
+			v, err := strconv.Unquote(f.s[f.pos : i+1])
+			f.pos = i + 1
+			return v, err
+		default:
+			v := strings.ReplaceAll(f.s[f.pos+1:i], "''", "'")
+			f.pos = i + 1
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("unterminated string %s", f.s[f.pos:])
+}
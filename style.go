@@ -0,0 +1,115 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"text/template"
+)
+
+// styleFile is the name of the file declaring InlineStyle, the typed inline style of the elements (-typed-style).
+const styleFile = "style.go"
+
+// styleAttr is the style property that replaces that of BasicHTMLElement in every element with -typed-style.
+var styleAttr = templAttr{Name: "Style", JS: "style", Type: "*InlineStyle"}
+
+// styleProps are the CSS properties of InlineStyle, typed as Length, Color, int or float64 where their values are
+// lengths, colors or numbers and as string otherwise.
+var styleProps = []templAttr{
+	{Name: "AlignItems", JS: "alignItems", Type: "string"},
+	{Name: "Background", JS: "background", Type: "string"},
+	{Name: "BackgroundColor", JS: "backgroundColor", Type: "Color"},
+	{Name: "Border", JS: "border", Type: "string"},
+	{Name: "BorderColor", JS: "borderColor", Type: "Color"},
+	{Name: "BorderRadius", JS: "borderRadius", Type: "Length"},
+	{Name: "BorderWidth", JS: "borderWidth", Type: "Length"},
+	{Name: "Bottom", JS: "bottom", Type: "Length"},
+	{Name: "BoxShadow", JS: "boxShadow", Type: "string"},
+	{Name: "BoxSizing", JS: "boxSizing", Type: "string"},
+	{Name: "Color", JS: "color", Type: "Color"},
+	{Name: "Cursor", JS: "cursor", Type: "string"},
+	{Name: "Display", JS: "display", Type: "string"},
+	{Name: "Flex", JS: "flex", Type: "string"},
+	{Name: "FlexDirection", JS: "flexDirection", Type: "string"},
+	{Name: "FlexGrow", JS: "flexGrow", Type: "float64"},
+	{Name: "FlexShrink", JS: "flexShrink", Type: "float64"},
+	{Name: "FlexWrap", JS: "flexWrap", Type: "string"},
+	{Name: "Float", JS: "float", Type: "string"},
+	{Name: "FontFamily", JS: "fontFamily", Type: "string"},
+	{Name: "FontSize", JS: "fontSize", Type: "Length"},
+	{Name: "FontStyle", JS: "fontStyle", Type: "string"},
+	{Name: "FontWeight", JS: "fontWeight", Type: "int"},
+	{Name: "Gap", JS: "gap", Type: "Length"},
+	{Name: "Height", JS: "height", Type: "Length"},
+	{Name: "JustifyContent", JS: "justifyContent", Type: "string"},
+	{Name: "Left", JS: "left", Type: "Length"},
+	{Name: "LineHeight", JS: "lineHeight", Type: "string"},
+	{Name: "Margin", JS: "margin", Type: "string"},
+	{Name: "MarginBottom", JS: "marginBottom", Type: "Length"},
+	{Name: "MarginLeft", JS: "marginLeft", Type: "Length"},
+	{Name: "MarginRight", JS: "marginRight", Type: "Length"},
+	{Name: "MarginTop", JS: "marginTop", Type: "Length"},
+	{Name: "MaxHeight", JS: "maxHeight", Type: "Length"},
+	{Name: "MaxWidth", JS: "maxWidth", Type: "Length"},
+	{Name: "MinHeight", JS: "minHeight", Type: "Length"},
+	{Name: "MinWidth", JS: "minWidth", Type: "Length"},
+	{Name: "Opacity", JS: "opacity", Type: "float64"},
+	{Name: "Overflow", JS: "overflow", Type: "string"},
+	{Name: "OverflowX", JS: "overflowX", Type: "string"},
+	{Name: "OverflowY", JS: "overflowY", Type: "string"},
+	{Name: "Padding", JS: "padding", Type: "string"},
+	{Name: "PaddingBottom", JS: "paddingBottom", Type: "Length"},
+	{Name: "PaddingLeft", JS: "paddingLeft", Type: "Length"},
+	{Name: "PaddingRight", JS: "paddingRight", Type: "Length"},
+	{Name: "PaddingTop", JS: "paddingTop", Type: "Length"},
+	{Name: "PointerEvents", JS: "pointerEvents", Type: "string"},
+	{Name: "Position", JS: "position", Type: "string"},
+	{Name: "Resize", JS: "resize", Type: "string"},
+	{Name: "Right", JS: "right", Type: "Length"},
+	{Name: "TextAlign", JS: "textAlign", Type: "string"},
+	{Name: "TextDecoration", JS: "textDecoration", Type: "string"},
+	{Name: "Top", JS: "top", Type: "Length"},
+	{Name: "Transform", JS: "transform", Type: "string"},
+	{Name: "Transition", JS: "transition", Type: "string"},
+	{Name: "Visibility", JS: "visibility", Type: "string"},
+	{Name: "WhiteSpace", JS: "whiteSpace", Type: "string"},
+	{Name: "Width", JS: "width", Type: "Length"},
+	{Name: "ZIndex", JS: "zIndex", Type: "int"},
+}
+
+// styleDecls are the identifiers declared in styleFile.
+var styleDecls = []string{"InlineStyle", "NewInlineStyle", "Length", "Pixels", "Ems", "Rems", "Percent"}
+
+// templStyle is the data of the template that declares InlineStyle.
+type templStyle struct {
+	templTarget
+
+	Props []templAttr
+}
+
+// generateStyle renders with base the declaration of InlineStyle into styleFile, and writes or diffs it according to
+// opts.
+func generateStyle(base *template.Template, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	generateShared(base, "style", styleFile, templStyle{templTarget: target, Props: styleProps}, opts, res, produced)
+}
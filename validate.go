@@ -0,0 +1,313 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// knownTypes are the Go types an attribute may have without validate reporting it, besides the eventHandlers,
+// valueTypes and enums. Other types must be declared by the target package.
+var knownTypes = map[string]bool{
+	"bool": true, "string": true,
+	"int": true, "int32": true, "int64": true,
+	"float32": true, "float64": true,
+}
+
+// specProblem is a problem found in a spec file at the byte offset Offset, or at an unknown position if Offset is
+// negative.
+type specProblem struct {
+	Offset int
+	Msg    string
+}
+
+// jsonWalker records where the values of a JSON document start, keyed by JSON pointer, and the duplicate object keys
+// that encoding/json would silently resolve in favor of the last one.
+type jsonWalker struct {
+	data []byte
+	dec  *json.Decoder
+	pos  map[string]int
+	dups []specProblem
+}
+
+// jsonPositions walks the JSON document data. The position of an object member is that of its key.
+func jsonPositions(data []byte) (map[string]int, []specProblem, error) {
+	w := &jsonWalker{data: data, dec: json.NewDecoder(bytes.NewReader(data)), pos: make(map[string]int)}
+	if err := w.value("", -1); err != nil {
+		return nil, nil, err
+	}
+
+	return w.pos, w.dups, nil
+}
+
+// next returns the offset at which the next token starts.
+func (w *jsonWalker) next() int {
+	off := int(w.dec.InputOffset())
+	for off < len(w.data) && strings.IndexByte(" \t\r\n,:", w.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// value walks the value at the pointer ptr, which starts at the offset at, or at the next token if at is negative.
+func (w *jsonWalker) value(ptr string, at int) error {
+	if at < 0 {
+		at = w.next()
+	}
+	w.pos[ptr] = at
+
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for w.dec.More() {
+			at := w.next()
+			tok, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			k := tok.(string)
+			if seen[k] {
+				w.dups = append(w.dups, specProblem{at, fmt.Sprintf("duplicate key %q", k)})
+			}
+			seen[k] = true
+			if err := w.value(ptr+"/"+k, at); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+	case json.Delim('['):
+		for i := 0; w.dec.More(); i++ {
+			if err := w.value(fmt.Sprintf("%s/%d", ptr, i), -1); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+	}
+
+	return err
+}
+
+// validateSpec checks the spec file contents data for mistakes that loading it either rejects without saying where
+// or accepts silently: duplicate keys, elements and attributes, attribute types the generator does not know,
+// overrides that repeat the default name, and identifiers that collide once the names are cased.
+func validateSpec(data []byte) []specProblem {
+	pos, problems, err := jsonPositions(data)
+	if err != nil {
+		return []specProblem{jsonProblem(err)}
+	}
+
+	spec, err := decodeSpec(bytes.NewReader(data))
+	if err != nil {
+		return append(problems, jsonProblem(err))
+	}
+	at := func(ptr string) int {
+		if p, ok := pos[ptr]; ok {
+			return p
+		}
+		return -1
+	}
+
+	namer := NewNamer(spec.Naming)
+	checkAttrs := func(ptr, what string, attrs []Attr) {
+		seen := make(map[string]bool)
+		for i, a := range attrs {
+			ap := fmt.Sprintf("%s/attributes/%d", ptr, i)
+			if strings.HasPrefix(ptr, "/groups/") {
+				ap = fmt.Sprintf("%s/%d", ptr, i)
+			}
+			switch {
+			case a.Name == "":
+				problems = append(problems, specProblem{at(ap), fmt.Sprintf("%s: attribute %d has an empty name", what, i)})
+				continue
+			case seen[a.Name]:
+				problems = append(problems, specProblem{at(ap), fmt.Sprintf("%s: duplicate attribute %q", what, a.Name)})
+			}
+			seen[a.Name] = true
+			_, event := eventHandlers[a.Type]
+			if a.Type != "" && !knownTypes[a.Type] && !event && !isValueType(a.Type) {
+				problems = append(problems, specProblem{at(ap + "/type"), fmt.Sprintf("%s: attribute %q has unknown type %q", what, a.Name, a.Type)})
+			}
+			if a.Override != "" && a.Override == namer.Name(a.Name) {
+				problems = append(problems, specProblem{at(ap + "/override"), fmt.Sprintf("%s: override %s of attribute %q does not change its name", what, a.Override, a.Name)})
+			}
+		}
+	}
+	checkElems := func(key string, elems map[string]Desc) {
+		for k, d := range elems {
+			ptr := "/" + key + "/" + k
+			what := "<" + k + ">"
+			if d.Override != "" && d.Override == namer.Name(k) {
+				problems = append(problems, specProblem{at(ptr + "/override"), fmt.Sprintf("%s: override %s does not change its name", what, d.Override)})
+			}
+			checkAttrs(ptr, what, d.Attributes)
+		}
+	}
+	checkElems("elements", spec.Elements)
+	checkElems("customElements", spec.CustomElements)
+	for name, attrs := range spec.Groups {
+		checkAttrs("/groups/"+name, fmt.Sprintf("attribute group %q", name), attrs)
+	}
+	// An unknown global group would be reported for every element, so it is reported once instead.
+	known, unknown := spec.AttrGroups(groups), false
+	for i, n := range spec.GlobalGroups {
+		if _, ok := known[n]; !ok {
+			problems = append(problems, specProblem{at(fmt.Sprintf("/globalGroups/%d", i)), fmt.Sprintf("unknown global attribute group %q", n)})
+			unknown = true
+		}
+	}
+	if unknown {
+		return problems
+	}
+
+	table, err := spec.Table(elements, known)
+	if err != nil {
+		return append(problems, specProblem{-1, err.Error()})
+	}
+	// The problems with single attributes have been reported above, so they are left out of the elements planned to
+	// find the collisions.
+	groups := spec.AttrGroups(groups)
+	for name, attrs := range groups {
+		groups[name] = uniqueAttrs(attrs)
+	}
+	tgroups, failures := newTemplGroups(groups, namer)
+	planned := make(map[string]templElem)
+	var tags []string
+	for k, d := range table {
+		if d.HandWritten {
+			continue
+		}
+		d.Attributes = uniqueAttrs(d.Attributes)
+		err := validateElem(k, d, groups)
+		if err == nil {
+			err = validateElemName(k, d, namer)
+		}
+		if err != nil {
+			failures = append(failures, Failure{Element: k, Phase: PhaseSpec, Err: err})
+			continue
+		}
+		planned[k] = newTemplElem(k, d, namer, tgroups)
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	failures = append(failures, findCollisions(tags, planned, reservedFor(Options{Elements: table}, planned), false, false)...)
+	for _, f := range failures {
+		if f.Element == "" {
+			problems = append(problems, specProblem{-1, f.Err.Error()})
+			continue
+		}
+		p := at("/elements/" + f.Element)
+		if p < 0 {
+			p = at("/customElements/" + f.Element)
+		}
+		problems = append(problems, specProblem{p, fmt.Sprintf("<%s>: %v", f.Element, f.Err)})
+	}
+
+	return problems
+}
+
+// uniqueAttrs returns the attributes among attrs that have a name, leaving out those whose name is repeated.
+func uniqueAttrs(attrs []Attr) []Attr {
+	var res []Attr
+	seen := make(map[string]bool)
+	for _, a := range attrs {
+		if a.Name != "" && !seen[a.Name] {
+			seen[a.Name] = true
+			res = append(res, a)
+		}
+	}
+
+	return res
+}
+
+// jsonProblem locates the JSON decoding error err if it carries an offset.
+func jsonProblem(err error) specProblem {
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		return specProblem{int(se.Offset), err.Error()}
+	case errors.As(err, &te):
+		return specProblem{int(te.Offset), err.Error()}
+	}
+	return specProblem{-1, err.Error()}
+}
+
+// position returns the 1-based line and column of the byte offset off in data.
+func position(data []byte, off int) (int, int) {
+	if off > len(data) {
+		off = len(data)
+	}
+	line := bytes.Count(data[:off], []byte("\n")) + 1
+	col := off - bytes.LastIndexByte(data[:off], '\n')
+	return line, col
+}
+
+// runValidate runs the validate command with the arguments args, checking each spec file given. It returns the exit
+// status.
+func runValidate(fs *flag.FlagSet, args []string) int {
+	if code, ok := parseArgs(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	code := 0
+	for _, p := range fs.Args() {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitIO
+		}
+
+		problems := validateSpec(data)
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].Offset < problems[j].Offset })
+		for _, sp := range problems {
+			if sp.Offset < 0 {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", p, sp.Msg)
+				continue
+			}
+			line, col := position(data, sp.Offset)
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", p, line, col, sp.Msg)
+		}
+		if len(problems) > 0 {
+			code = exitSpec
+		}
+	}
+
+	return code
+}
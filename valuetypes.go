@@ -0,0 +1,157 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"sort"
+	"text/template"
+)
+
+// valueType is a type of attribute values that is declared by a generated file, rather than by the target package,
+// when an attribute of the planned elements has it. Its underlying type is string, so that attribute values written
+// in HTML convert to it directly. Template names the template that renders File, which declares the type and the
+// identifiers Decls that build its values. When a file declares several value types, only one of them lists Decls.
+// Valid reports that the type has a Valid method, which Debug calls.
+type valueType struct {
+	File, Template string
+	Decls          []string
+	Valid          bool
+}
+
+// valueTypes maps the names of the value types to the files declaring them.
+var valueTypes = map[string]valueType{
+	"AcceptList": {
+		File:     "accept.go",
+		Template: "accept",
+		Decls: []string{"AcceptList", "Accept", "MIMEType", "FileExtension", "AnyAudio", "AnyImage", "AnyVideo",
+			"CSV", "GIF", "JPEG", "JSONType", "PDF", "PlainText", "PNG", "SVG", "WebP"},
+	},
+	"AutoComplete": {
+		File:     "autocomplete.go",
+		Template: "autofill",
+		Decls: []string{"AutoComplete", "AutoCompleteOn", "AutoCompleteOff", "AutofillField", "AutofillContact",
+			"AutofillHint", "Autofill", "AutofillHome", "AutofillWork", "AutofillMobile", "AutofillFax", "AutofillPager"},
+		Valid: true,
+	},
+	"Bound": {
+		File:     "constraint.go",
+		Template: "constraint",
+		Decls:    []string{"Bound", "NumberBound", "DateBound", "DateTimeBound", "MonthBound", "TimeBound", "StepAny"},
+	},
+	"Color": {
+		File:     "color.go",
+		Template: "color",
+		Decls:    []string{"Color", "RGB", "RGBA"},
+		Valid:    true,
+	},
+	"DateTime": {
+		File:     "datetime.go",
+		Template: "datetime",
+		Decls:    []string{"DateTime", "DateTimeOf", "DateOf", "DurationOf"},
+	},
+	"Dimension": {
+		File:     "dimension.go",
+		Template: "dimension",
+		Decls:    []string{"Dimension", "DimensionPixels", "DimensionPercent"},
+		Valid:    true,
+	},
+	"PermissionsPolicy": {
+		File:     "allow.go",
+		Template: "allow",
+		Decls: []string{"PermissionsPolicy", "Feature", "PolicyOrigin", "Permit", "AnyOrigin", "NoOrigin", "SelfOrigin",
+			"SrcOrigin", "Accelerometer", "Autoplay", "Camera", "ClipboardRead", "ClipboardWrite", "DisplayCapture",
+			"EncryptedMedia", "Fullscreen", "Geolocation", "Gyroscope", "Magnetometer", "Microphone", "MIDI", "Payment",
+			"PictureInPicture", "PublicKeyCredentialsGet", "ScreenWakeLock", "USB", "WebShare"},
+	},
+	"SandboxPolicy": {
+		File:     "sandbox.go",
+		Template: "sandbox",
+		Decls: []string{"Sandbox", "SandboxPolicy", "AllowDownloads", "AllowForms", "AllowModals", "AllowOrientationLock",
+			"AllowPointerLock", "AllowPopups", "AllowPopupsToEscapeSandbox", "AllowPresentation", "AllowSameOrigin",
+			"AllowScripts", "AllowStorageAccessByUserActivation", "AllowTopNavigation",
+			"AllowTopNavigationByUserActivation", "AllowTopNavigationToCustomProtocols"},
+	},
+	"Sizes": {
+		File:     "srcset.go",
+		Template: "srcset",
+	},
+	"SrcSet": {
+		File:     "srcset.go",
+		Template: "srcset",
+		Decls:    []string{"SrcSet", "ImageCandidate", "NewSrcSet", "Sizes", "SourceSize", "NewSizes"},
+	},
+	"URL": {
+		File:     "url.go",
+		Template: "url",
+		Decls:    []string{"URL", "URLOf", "AllowURLScheme"},
+		Valid:    true,
+	},
+}
+
+// Typed reports whether the attribute's Go type is one of the valueTypes or enums.
+func (a templAttr) Typed() bool {
+	return isValueType(a.Type)
+}
+
+// isValueType reports whether t names one of the valueTypes or enums.
+func isValueType(t string) bool {
+	_, ok := valueTypes[t]
+	_, enum := enums[t]
+	return ok || enum
+}
+
+// generateValueTypes renders with base the file of every valueType that an attribute of the planned elements or, with
+// -typed-style, a property of InlineStyle has, and writes or diffs them according to opts.
+func generateValueTypes(base *template.Template, tags []string, planned map[string]templElem, target templTarget, opts Options, res *Result, produced map[string]bool) {
+	used := make(map[string]bool)
+	for _, k := range tags {
+		for _, a := range planned[k].ElemAttrs() {
+			if _, ok := valueTypes[a.Type]; ok {
+				used[a.Type] = true
+			}
+		}
+	}
+
+	if opts.TypedStyle {
+		for _, a := range styleProps {
+			if _, ok := valueTypes[a.Type]; ok {
+				used[a.Type] = true
+			}
+		}
+	}
+
+	var names []string
+	for t := range used {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	files := make(map[string]bool)
+	for _, t := range names {
+		if vt := valueTypes[t]; !files[vt.File] {
+			files[vt.File] = true
+			generateShared(base, vt.Template, vt.File, target, opts, res, produced)
+		}
+	}
+}
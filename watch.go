@@ -0,0 +1,117 @@
+/*
+ * MIT LICENSE
+ *
+ * Copyright © 2018, G.Ralph Kuntz, MD.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package elemental
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchedFiles returns the files and directories given on the command line whose changes affect the generated
+// output.
+func watchedFiles() []string {
+	var paths []string
+	for _, p := range append([]string{*config, *headerFile, *reservedFile, *overrideDir, *templateDir}, overlays...) {
+		if p != "" && p != "-" && !isURL(p) {
+			paths = append(paths, p)
+		}
+	}
+	if *templateDir != "" {
+		paths = append(paths, filepath.Join(*templateDir, "element"))
+	}
+
+	return paths
+}
+
+// modTimes returns the modification time of each of paths and, for directories, of each of their entries. Missing
+// paths are left out, so that deleting a file counts as a change.
+func modTimes(paths []string) map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		times[p] = fi.ModTime()
+		if !fi.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if fi, err := e.Info(); err == nil {
+				times[filepath.Join(p, e.Name())] = fi.ModTime()
+			}
+		}
+	}
+
+	return times
+}
+
+// sameTimes reports whether a and b record the same paths with the same modification times.
+func sameTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, t := range a {
+		if u, ok := b[p]; !ok || !u.Equal(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// watchAndRun generates the element wrappers and then polls the watched files every interval, generating again
+// whenever one of them changes. It returns only if there is nothing to watch.
+func watchAndRun(interval time.Duration) int {
+	if *config == "-" {
+		fmt.Fprintln(os.Stderr, "-watch cannot read the spec from standard input")
+		return exitUsage
+	}
+	paths := watchedFiles()
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "-watch needs a -config, -overlay, -header, -reserved, -overrides or -templates path to watch")
+		return exitUsage
+	}
+
+	last := modTimes(paths)
+	run()
+	for {
+		time.Sleep(interval)
+		cur := modTimes(paths)
+		if sameTimes(last, cur) {
+			continue
+		}
+		last = cur
+
+		logger.Info("inputs changed; regenerating")
+		run()
+	}
+}